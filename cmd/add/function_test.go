@@ -1,6 +1,7 @@
 package add_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -9,27 +10,27 @@ import (
 )
 
 func TestFunctionCmd(t *testing.T) {
-	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	folder := filepath.Join(wd, "test")
 	if err != nil {
 		remove(folder)
 	}
 
-	// copy conf file
-	confFilePath := filepath.Join(wd, "dql.conf.json")
-	copy(filepath.Join(folder, "dql.conf.json"), confFilePath)
 	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "schema", "api")
 	if err != nil {
-		remove(folder, confFilePath)
+		remove(folder)
 	}
 
 	// execute command to test
 	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "function", "register", "-p", "/register", "-m", "post")
 	if err != nil {
-		remove(folder, confFilePath)
+		remove(folder)
 	}
 	// TODO: test Config
 
-	remove(folder, confFilePath)
+	remove(folder)
 }
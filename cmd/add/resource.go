@@ -22,12 +22,7 @@ package add
 
 import (
 	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 
-	"github.com/crolly/dynQL/cmd/helpers"
 	"github.com/crolly/dynQL/cmd/models"
 	"github.com/spf13/cobra"
 )
@@ -39,9 +34,21 @@ var (
 		Short: "Add a CRUDL resource",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// repeatable flags append to their previous values, reset them for the next execution (e.g. by apply)
+			defer func() {
+				gsi, lsi, hasOne, hasMany = nil, nil, nil, nil
+				versioned, consistentRead = false, false
+				pk, sk = "", ""
+			}()
+
 			if len(keySchema) == 0 {
 				return errors.New("KeySchema must be defined")
 			}
+			c, err := models.ReadDQLConfig()
+			if err != nil {
+				return err
+			}
+
 			// instantiate new resource model and parse given attributes
 			modelName := args[0]
 			capacityUnits := map[string]int64{
@@ -49,9 +56,20 @@ var (
 				"write": writeUnits,
 			}
 			options := map[string]interface{}{
-				"keySchema": keySchema,
-				"billing":   billingMode,
-				"capacity":  capacityUnits,
+				"keySchema":      keySchema,
+				"billing":        billingMode,
+				"capacity":       capacityUnits,
+				"gsi":            gsi,
+				"lsi":            lsi,
+				"hasOne":         hasOne,
+				"hasMany":        hasMany,
+				"schema":         schema,
+				"versioned":      versioned,
+				"consistentRead": consistentRead,
+				// resources in single-table mode build the keys of the shared table from the key templates
+				"singleTable": len(c.SharedTable(schema)) > 0,
+				"pk":          pk,
+				"sk":          sk,
 			}
 			m, err := models.New(modelName, false, attributes, options)
 			if err != nil {
@@ -60,20 +78,33 @@ var (
 			m.GetImports()
 
 			// add fields initialization to schema.go
-			c, err := m.GetConfig()
+			c, err = m.GetConfig()
 			if err != nil {
 				return err
 			}
+			c.Resources[m.Name].Schema = schema
 
 			// render templates
-			renderResourceTemplates(c, m, schema)
+			err = m.Render(c, schema)
+			if err != nil {
+				return err
+			}
+
+			// persist model definition
+			err = m.Write(c.ProjectPath)
+			if err != nil {
+				return err
+			}
 
 			// update serverless.yml
 			s, err := c.ReadServerlessConfig()
 			if err != nil {
 				return err
 			}
-			s.SetResourceWithModel(c, m)
+			err = s.SetResourceWithModel(c, m)
+			if err != nil {
+				return err
+			}
 			err = s.Write()
 			if err != nil {
 				return err
@@ -87,83 +118,28 @@ var (
 	schema                             string
 	attributes, keySchema, billingMode string
 	readUnits, writeUnits              int64
+	gsi, lsi                           []string
+	hasOne, hasMany                    []string
+	versioned, consistentRead          bool
+	pk, sk                             string
 )
 
 func init() {
 	AddCmd.AddCommand(resourceCmd)
 	resourceCmd.Flags().StringVarP(&schema, "schema", "s", "", "Name of the Schema the Resource will be added to")
-	resourceCmd.Flags().StringVarP(&attributes, "attributes", "a", "", "Attribute Definition of the Resource")
+	resourceCmd.Flags().StringVarP(&attributes, "attributes", "a", "", "Attribute Definition of the Resource as name[:type][!][=default], required attributes end with !, attributes of the type ref(Resource) or refs(Resource) reference other resources, enum(A,B) declares an enum type")
 	resourceCmd.Flags().StringVarP(&keySchema, "keySchema", "k", "id:HASH", "Key Schema Definition for the DynamoDB Table Resource (not compatible with generateID)")
 	resourceCmd.Flags().StringVarP(&billingMode, "billingMode", "b", "provisioned", "Choose between 'provisioned' for ProvisionedThroughput or 'ondemand'")
 	resourceCmd.Flags().Int64VarP(&readUnits, "readUnits", "r", 1, "Set the ReadCapacityUnits if billingMode is set to ProvisionedThroughput")
 	resourceCmd.Flags().Int64VarP(&writeUnits, "writeUnits", "w", 1, "Set the WriteCapacityUnits if billingMode is set to ProvisionedThroughput")
+	resourceCmd.Flags().StringArrayVar(&gsi, "gsi", nil, "Global Secondary Index Definition e.g. 'name=email;keySchema=email:HASH;projection=INCLUDE;nonKey=name;read=2;write=2' (repeatable)")
+	resourceCmd.Flags().StringArrayVar(&lsi, "lsi", nil, "Local Secondary Index Definition e.g. 'name=date;keySchema=id:HASH,date:RANGE;projection=KEYS_ONLY' (repeatable)")
+	resourceCmd.Flags().StringArrayVar(&hasOne, "has-one", nil, "Has-One Relation Definition e.g. 'profile:Profile:user_id' (repeatable)")
+	resourceCmd.Flags().StringArrayVar(&hasMany, "has-many", nil, "Has-Many Relation Definition e.g. 'lessons:Lesson:course_id' (repeatable)")
+	resourceCmd.Flags().StringVar(&pk, "pk", "", "Template of the partition key in single-table mode e.g. 'USER#{id}' (defaults to the type and the Hash Key)")
+	resourceCmd.Flags().StringVar(&sk, "sk", "", "Template of the sort key in single-table mode e.g. 'PROFILE' or 'ORDER#{created}' (defaults to the type and the Range Key)")
+	resourceCmd.Flags().BoolVar(&versioned, "versioned", false, "Add a version attribute and reject Puts of outdated versions (optimistic locking)")
+	resourceCmd.Flags().BoolVar(&consistentRead, "consistent-read", false, "Read strongly consistent by default, the consistentRead argument of a query overrides it (global indexes are always read eventually consistent)")
 
 	resourceCmd.MarkFlagRequired("schema")
 }
-
-func renderResourceTemplates(config *models.DQLConfig, model *models.Model, schema string) error {
-	templates := map[string][]string{
-		"models": {
-			"model",
-			"model_test",
-			"resource",
-			"resource_test",
-		},
-		"schema": {
-			"schema",
-			"modelSchema",
-			"modelSchema_test",
-		},
-		"services": {
-			"dynamo",
-			"service",
-			"service_test",
-		},
-		"main": {
-			"main_test",
-		},
-	}
-
-	data := map[string]interface{}{
-		"Config": config,
-		"Model":  model,
-		"Schema": schema,
-	}
-
-	projPath := helpers.GetProjectPath(config.ProjectPath)
-
-	// iterate over schema templates and execute
-	for g, ts := range templates {
-		var f string
-		if g == "schema" {
-			f = filepath.Join(projPath, "handler", schema, "schema")
-		} else if g == "main" {
-			f = filepath.Join(projPath, "handler", schema)
-		} else {
-			f = filepath.Join(projPath, g)
-		}
-		err := os.MkdirAll(f, 0755)
-		if err != nil {
-			return err
-		}
-		for _, t := range ts {
-			tFile := t + ".tmpl"
-			fFile := t + ".go"
-			switch t {
-			case "resource", "resource_test":
-				fFile = strings.ReplaceAll(t, "resource", model.Ident.Camelize().String()) + ".go"
-			case "modelSchema", "modelSchema_test":
-				fFile = strings.ReplaceAll(t, "modelSchema", model.Ident.Camelize().String()) + ".go"
-			case "service", "service_test":
-				fFile = strings.ReplaceAll(t, "service", model.Ident.Camelize().String()) + ".go"
-			}
-			err = helpers.RenderFile(helpers.ResourceBox, fFile, tFile, f, data)
-			if err != nil {
-				fmt.Println(err)
-				return err
-			}
-		}
-	}
-
-	return nil
-}
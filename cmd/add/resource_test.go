@@ -1,41 +1,464 @@
 package add_test
 
 import (
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/crolly/dynQL/cmd"
 	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/gobuffalo/flect"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestResourceCmd(t *testing.T) {
-	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	folder := filepath.Join(wd, "test")
 	if err != nil {
 		remove(folder)
 	}
 
-	// copy conf file
-	confFilePath := filepath.Join(wd, "dql.conf.json")
-	copy(filepath.Join(folder, "dql.conf.json"), confFilePath)
 	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "schema", "api")
 	if err != nil {
-		remove(folder, confFilePath)
+		remove(folder)
 	}
 
 	// execute command to test
 	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "user", "-s", "api", "-a", "id,name,address:{street,zip,city}")
 	if err != nil {
-		remove(folder, confFilePath)
+		remove(folder)
 	}
-
-	copy(filepath.Join(folder, "dql.conf.json"), confFilePath)
 	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "course", "-s", "api", "-a", "id,name,items,date:*time.Time:[id,name,timestamp:int64]")
 	if err != nil {
-		remove(folder, confFilePath)
+		remove(folder)
 	}
 	// TODO: test Config
 
-	remove(folder, confFilePath)
+	remove(folder)
+}
+
+func TestResourceCmdPreservesCustomRegions(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "user", "-s", "api", "-a", "id,name")
+	assert.NoError(t, err)
+
+	// add custom code to the generated model
+	f := filepath.Join(folder, "models", "user.go")
+	data, _ := helpers.ReadDataFromFile(f)
+	custom := "func (u User) Greeting() string {\n\treturn \"Hello \" + u.Name\n}\n"
+	region := helpers.CustomRegionBegin + "methods\n"
+	data = []byte(strings.Replace(string(data), region, region+custom, 1))
+	err = ioutil.WriteFile(f, data, 0644)
+	assert.NoError(t, err)
+
+	// regenerate the resource
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "user", "-s", "api", "-a", "id,name,email")
+	assert.NoError(t, err)
+
+	data, _ = helpers.ReadDataFromFile(f)
+	assert.Contains(t, string(data), custom)
+	assert.Contains(t, string(data), "Email string")
+
+	// a region which is not closed or has no place in the regenerated file fails the regeneration and leaves the file untouched
+	for _, broken := range []string{
+		strings.Replace(string(data), helpers.CustomRegionEnd+"methods", helpers.CustomRegionEnd+"method", 1),
+		string(data) + helpers.CustomRegionBegin + "extra\nvar extra = 1\n" + helpers.CustomRegionEnd + "extra\n",
+	} {
+		err = ioutil.WriteFile(f, []byte(broken), 0644)
+		assert.NoError(t, err)
+		_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "user", "-s", "api", "-a", "id,name,age:int")
+		assert.Error(t, err)
+		data, _ = helpers.ReadDataFromFile(f)
+		assert.Equal(t, broken, string(data))
+	}
+}
+
+func TestResourceCmdWithIndexes(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "event", "-s", "api", "-a", "room,ts:int64,host,title",
+		"-k", "room:HASH,ts:RANGE",
+		"--gsi", "name=host;keySchema=host:HASH,ts:RANGE;projection=INCLUDE;nonKey=title;read=2;write=3",
+		"--lsi", "name=title;keySchema=room:HASH,title:RANGE;projection=KEYS_ONLY")
+	assert.NoError(t, err)
+
+	s, err := helpers.ReadDataFromFile(filepath.Join(folder, "serverless.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(s), "IndexName: host")
+	assert.Contains(t, string(s), "IndexName: title")
+	assert.Contains(t, string(s), "ProvisionedThroughput:\n            ReadCapacityUnits: 2\n            WriteCapacityUnits: 3")
+	assert.Contains(t, string(s), "NonKeyAttributes:\n            - title")
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "event.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `queryFields["EventsByRoom"]`)
+	assert.Contains(t, string(data), `queryFields["EventsByHost"]`)
+	assert.Contains(t, string(data), `queryFields["EventsByTitle"]`)
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "services", "event.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"host": {hashName: "host", rangeName: "ts", global: true}`)
+
+	// local indexes require the hash key of the table
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "event", "-s", "api", "-a", "room,ts:int64,host,title",
+		"-k", "room:HASH,ts:RANGE",
+		"--lsi", "name=title;keySchema=host:HASH,title:RANGE")
+	assert.Error(t, err)
+
+	// options may be surrounded by spaces
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "slot", "-s", "api", "-a", "id,host,starts,room,title", "-k", "id:HASH",
+		"--gsi", "name = host; keySchema = host : HASH, starts : RANGE; projection = INCLUDE; nonKey = room, title; read = 4; write = 5")
+	assert.NoError(t, err)
+	s, err = helpers.ReadDataFromFile(filepath.Join(folder, "serverless.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(s), "ProvisionedThroughput:\n            ReadCapacityUnits: 4\n            WriteCapacityUnits: 5")
+	assert.Contains(t, string(s), "NonKeyAttributes:\n            - room\n            - title")
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "services", "slot.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"host": {hashName: "host", rangeName: "starts", global: true}`)
+}
+
+func TestResourceCmdWithRelations(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "teacher", "-s", "api", "-a", "id,name", "-k", "id:HASH")
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "lesson", "-s", "api", "-a", "id,course_id,title", "-k", "id:HASH",
+		"--gsi", "name=course;keySchema=course_id:HASH")
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "course", "-s", "api", "-a", "id,name,teacher_id:ref(Teacher)", "-k", "id:HASH",
+		"--has-many", "lessons:Lesson:course_id")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "course.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"teacher": "teacher_id",`)
+	assert.Contains(t, string(data), `"lessons": "id",`)
+	assert.Contains(t, string(data), "func GetCourseTeacher(params graphql.ResolveParams) func() (*Teacher, error)")
+	assert.Contains(t, string(data), "services.TeacherService(teacherHashName).Load(params.Context, related, course.TeacherID)")
+	assert.Contains(t, string(data), `QueryWithIndex(&related, selects, page, filter, "course", services.Equal, course.ID)`)
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "course.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `courseType.AddFieldConfig("teacher"`)
+	assert.Contains(t, string(data), `courseType.AddFieldConfig("lessons"`)
+
+	// the handler wires a loader into every request
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "main.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "services.WithLoader(context.Background(), services.NewLoader())")
+
+	// the related resource has to exist and be queryable by the foreign key
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "course", "-s", "api", "-a", "id,name,room_id:ref(Room)", "-k", "id:HASH")
+	assert.Error(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "course", "-s", "api", "-a", "id,name", "-k", "id:HASH",
+		"--has-many", "lessons:Lesson:title")
+	assert.Error(t, err)
+}
+
+func TestResourceCmdVersioned(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "article", "-s", "api", "-a", "id,title", "-k", "id:HASH", "--versioned")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "article.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Version int64 `json:\"version,omitempty\" dynamo:\"version,omitempty\"`")
+	assert.Contains(t, string(data), "PutVersion(article, articleVersionName, expected)")
+	assert.Contains(t, string(data), "UpdateVersion(article, set, remove, articleVersionName, patch.Version, "+flect.Camelize("id")+")")
+
+	// batch writes cannot check the version
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "article.go"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), `mutationFields["batchPutArticles"]`)
+	assert.Contains(t, string(data), `mutationFields["batchDeleteArticles"]`)
+
+	// the version attribute is reserved
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "article", "-s", "api", "-a", "id,title,version:int", "-k", "id:HASH", "--versioned")
+	assert.Error(t, err)
+
+	// the flag does not apply to the next resource
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "comment", "-s", "api", "-a", "id,text", "-k", "id:HASH")
+	assert.NoError(t, err)
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "models", "comment.go"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "Version")
+}
+
+func TestResourceCmdMutations(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "article", "-s", "api", "-a", "id,title", "-k", "id:HASH")
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "marker", "-s", "api", "-a", "id", "-k", "id:HASH")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "article.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `mutationFields["createArticle"]`)
+	assert.Contains(t, string(data), `mutationFields["updateArticle"]`)
+	assert.Contains(t, string(data), `mutationFields["upsertArticle"]`)
+	assert.NotContains(t, string(data), `mutationFields["Article"]`)
+	assert.Contains(t, string(data), `queryFields["batchGetArticles"]`)
+	assert.Contains(t, string(data), `mutationFields["batchPutArticles"]`)
+	assert.Contains(t, string(data), `mutationFields["batchDeleteArticles"]`)
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "models", "article.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "services.ArticleService(articleHashName).Create(article)")
+	assert.Contains(t, string(data), "services.ArticleService(articleHashName).Update(article, set, remove, "+flect.Camelize("id")+")")
+	assert.Contains(t, string(data), "func ArticleTransactOperations(input, key, patch, filter *graphql.InputObject) []*TransactOperation")
+
+	// the operations of all resources of the schema are combined in the transact mutation
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "schema.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `mutationFields["transact"] = models.TransactField(transactOperations)`)
+
+	// a resource without fields besides its keys cannot be updated
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "marker.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `mutationFields["createMarker"]`)
+	assert.NotContains(t, string(data), `mutationFields["updateMarker"]`)
+	assert.Contains(t, string(data), "models.MarkerTransactOperations(markerInputType, markerKeyType, markerFilterType)")
+}
+
+func TestResourceCmdSingleTable(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "schema", "api", "--single-table")
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "teacher", "-s", "api", "-a", "id,name,email", "-k", "id:HASH",
+		"--gsi", "name=byEmail;keySchema=email:HASH;overload=gsi1;pk=EMAIL#{email}")
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "order", "-s", "api", "-a", "user_id,created", "-k", "user_id:HASH,created:RANGE",
+		"--pk", "USER#{user_id}", "--sk", "ORDER#{created}")
+	assert.NoError(t, err)
+
+	// all resources of the schema share one table with an overloaded index
+	s, err := helpers.ReadDataFromFile(filepath.Join(folder, "serverless.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(s), "TestAPISingleTable:")
+	assert.Contains(t, string(s), "TEACHER_TABLE_NAME: test-api-${opt:stage, self:provider.stage}")
+	assert.Contains(t, string(s), "ORDER_TABLE_NAME: test-api-${opt:stage, self:provider.stage}")
+	assert.Contains(t, string(s), "IndexName: gsi1")
+	assert.NotContains(t, string(s), "TeacherDynamoDbTable")
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "services", "teacher.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `overload: "gsi1", partitionKey: "EMAIL#{email}", sortKey: "TEACHER"`)
+	assert.Contains(t, string(data), `partitionKey: "TEACHER#{id}",`)
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "services", "order.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `partitionKey: "USER#{user_id}",`)
+	assert.Contains(t, string(data), `sortKey:      "ORDER#{created}",`)
+
+	// the key templates may only contain the keys of the resource
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "order", "-s", "api", "-a", "user_id,created", "-k", "user_id:HASH,created:RANGE",
+		"--pk", "USER#{created}")
+	assert.Error(t, err)
+	// the keys of the shared table are reserved
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "tag", "-s", "api", "-a", "id,pk", "-k", "id:HASH")
+	assert.Error(t, err)
+	// global indexes have to be overloaded
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "tag", "-s", "api", "-a", "id,name", "-k", "id:HASH",
+		"--gsi", "name=byName;keySchema=name:HASH")
+	assert.Error(t, err)
+	// the table mode cannot change while the schema has resources
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "schema", "api")
+	assert.Error(t, err)
+}
+
+func TestResourceCmdScalars(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "event", "-s", "api",
+		"-a", "room,start:time.Time,end:*time.Time,token:uuid.UUID,meta:json.RawMessage,size:int64", "-k", "room:HASH,start:RANGE")
+	assert.NoError(t, err)
+
+	// times are stored as strings
+	s, err := helpers.ReadDataFromFile(filepath.Join(folder, "serverless.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(s), "- AttributeName: start\n          AttributeType: S")
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "event.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "End *time.Time `json:\"end,omitempty\" dynamo:\"end,omitempty\"`")
+	assert.Contains(t, string(data), `"github.com/gofrs/uuid"`)
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "models", "scalars.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `Name:        "DateTime",`)
+	assert.Contains(t, string(data), `Name:        "Int64",`)
+}
+
+func TestResourceCmdConstraints(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "post", "-s", "api", "-a", "id,title:string!,status=draft,views:int!=0", "-k", "id:HASH")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "post.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "ID string `json:\"id\" dynamo:\"id\" dynql:\"key\"`")
+	assert.Contains(t, string(data), "Title string `json:\"title\" dynamo:\"title\" dynql:\"required\"`")
+	assert.Contains(t, string(data), "Status string `json:\"status,omitempty\" dynamo:\"status,omitempty\" dynql:\"default=draft\"`")
+	assert.Contains(t, string(data), "Views int `json:\"views\" dynamo:\"views\" dynql:\"required,default=0\"`")
+
+	// the default value has to match the type of the attribute
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "comment", "-s", "api", "-a", "id,likes:int=many")
+	assert.Error(t, err)
+}
+
+func TestResourceCmdTypedKeys(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "reading", "-s", "api",
+		"-a", "sensor:uuid.UUID,at:int64,blob:[]byte,score:float64", "-k", "sensor:HASH,at:RANGE", "--gsi", "name=blob;keySchema=blob:HASH,score:RANGE")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "reading.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Blob []byte")
+	assert.Contains(t, string(data), `"at": Int64,`)
+	assert.Contains(t, string(data), `"blob": Base64,`)
+	assert.Contains(t, string(data), `"score": graphql.Float,`)
+	assert.Contains(t, string(data), `"sensor": UUID,`)
+	assert.Contains(t, string(data), "sensor, _ := params.Args[readingHashName].(uuid.UUID)")
+	assert.Contains(t, string(data), "at, _ := params.Args[readingRangeName].(int64)")
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "reading.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `Type:        graphql.NewNonNull(models.GetReadingKeyArgType("at")),`)
+	assert.Contains(t, string(data), `models.GetReadingKeyArgType("score")`)
+}
+
+func TestResourceCmdEnums(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "post", "-s", "api", "-a", "id,status:enum(DRAFT,PUBLISHED)=DRAFT,title", "-k", "id:HASH")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "post.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Status PostStatus `json:\"status,omitempty\" dynamo:\"status,omitempty\" dynql:\"default=DRAFT\"`")
+	assert.Contains(t, string(data), "type PostStatus string")
+	assert.Contains(t, string(data), `PostStatusDraft PostStatus = "DRAFT"`)
+	assert.Contains(t, string(data), `var PostStatusEnum = newEnum("PostStatus", "The Status of a Post", PostStatusDraft, PostStatusPublished)`)
+
+	// the default value has to be a value of the enum
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "comment", "-s", "api", "-a", "id,state:enum(OPEN,CLOSED)=DONE", "-k", "id:HASH")
+	assert.Error(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "comment", "-s", "api", "-a", "id,state:enum(OPEN,OPEN)", "-k", "id:HASH")
+	assert.Error(t, err)
+}
+
+func TestResourceCmdConsistentRead(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer remove(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "post", "-s", "api", "-a", "id,date,author", "-k", "id:HASH,date:RANGE",
+		"--gsi", "name=author;keySchema=author:HASH", "--lsi", "name=recent;keySchema=id:HASH,author:RANGE", "--consistent-read")
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "models", "post.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"consistent_read": true`)
+
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "services", "post.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "consistentRead: true,")
+	assert.Contains(t, string(data), `"author": {hashName: "author", rangeName: "", global: true},`)
+
+	// global indexes cannot be read strongly consistent
+	data, err = helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "post.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Args: models.ConsistentReadArgs(graphql.FieldConfigArgument{")
+	assert.Contains(t, string(data), "models.FilterArgs(models.PageArgs(models.ConsistentReadArgs(nil)), postFilterType)")
+	assert.Contains(t, string(data), "models.FilterArgs(models.PageArgs(models.RangeQueryArgs(models.ConsistentReadArgs(graphql.FieldConfigArgument{")
+	assert.Contains(t, string(data), "models.FilterArgs(models.PageArgs(graphql.FieldConfigArgument{")
 }
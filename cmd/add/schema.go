@@ -21,6 +21,7 @@
 package add
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,6 +40,11 @@ var (
 		Short: "Add a schema to the project",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// flags keep their values between executions (e.g. by apply)
+			defer func() {
+				singleTable = false
+			}()
+
 			// get schema name
 			schemaName := args[0]
 			// make sure path is set
@@ -50,7 +56,16 @@ var (
 			if err != nil {
 				return err
 			}
+			// the resources of a schema are stored in the tables of its mode
+			if s, ok := c.Schemas[schemaName]; ok && s.SingleTable != singleTable {
+				for n, r := range c.Resources {
+					if r.Schema == schemaName {
+						return fmt.Errorf("The table mode of schema %s cannot be changed while it has resources like %s", schemaName, n)
+					}
+				}
+			}
 			c.AddSchema(schemaName, strings.TrimPrefix(path, "/"))
+			c.Schemas[schemaName].SingleTable = singleTable
 			err = c.Write()
 			if err != nil {
 				return err
@@ -59,11 +74,14 @@ var (
 			return renderSchemaTemplates(c, schemaName)
 		},
 	}
+
+	singleTable bool
 )
 
 func init() {
 	AddCmd.AddCommand(schemaCmd)
 	schemaCmd.Flags().StringVarP(&path, "path", "p", "", "Path under which the Schema will be available")
+	schemaCmd.Flags().BoolVar(&singleTable, "single-table", false, "Store all resources of the Schema in one shared DynamoDB table (single-table design)")
 }
 
 func renderSchemaTemplates(config *models.DQLConfig, schema string) error {
@@ -80,7 +98,7 @@ func renderSchemaTemplates(config *models.DQLConfig, schema string) error {
 	}
 
 	// iterate over schema templates and execute
-	folder := filepath.Join(helpers.GetProjectPath(config.ProjectPath), "handler", schema)
+	folder := filepath.Join(config.ProjectPath, "handler", schema)
 	for _, t := range templates {
 		err := os.MkdirAll(folder, 0755)
 		if err != nil {
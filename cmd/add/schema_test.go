@@ -1,6 +1,7 @@
 package add_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -10,25 +11,24 @@ import (
 )
 
 func TestSchemaCmd(t *testing.T) {
-	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	folder := filepath.Join(wd, "test")
 	if err != nil {
 		remove(folder)
 	}
 
-	// copy conf file
-	confFilePath := filepath.Join(wd, "dql.conf.json")
-	copy(filepath.Join(folder, "dql.conf.json"), confFilePath)
-
 	// execute command to test
 	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "schema", "api")
 	if err != nil {
-		remove(folder, confFilePath)
+		remove(folder)
 	}
 	assert.FileExists(t, filepath.Join(folder, "serverless.yml"))
 
-	sFolder := filepath.Join(folder, "schemas", "api")
+	sFolder := filepath.Join(folder, "handler", "api")
 	assert.DirExists(t, sFolder)
 	assert.DirExists(t, filepath.Join(sFolder, "schema"))
 	assert.FileExists(t, filepath.Join(sFolder, "main.go"))
@@ -36,5 +36,5 @@ func TestSchemaCmd(t *testing.T) {
 
 	// TODO: test ServerlessConfig
 
-	remove(folder, confFilePath)
+	remove(folder)
 }
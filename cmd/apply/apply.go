@@ -0,0 +1,262 @@
+// Copyright © 2019 Christian Rolly <mail@chromium-solutions.de>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package apply
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/crolly/dynQL/cmd/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ApplyCmd represents the apply command
+	ApplyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Applies the project manifest (dynql.yaml) to the project",
+		Long:  `This command compares the schemas, resources and functions declared in dynql.yaml with the project and adds, updates or removes them accordingly`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := models.ReadDQLConfig()
+			if err != nil {
+				return err
+			}
+
+			// run everything from the project root
+			err = os.Chdir(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+
+			m, err := models.ReadManifest(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+			s, err := c.ReadServerlessConfig()
+			if err != nil {
+				return err
+			}
+
+			steps, err := plan(c, s, m)
+			if err != nil {
+				return err
+			}
+			if len(steps) == 0 {
+				fmt.Println("Project is up to date with " + models.ManifestFile)
+				return nil
+			}
+
+			for _, st := range steps {
+				fmt.Println(st)
+				if dryRun {
+					continue
+				}
+				_, err = helpers.ExecuteCommand(cmd.Root(), st.args...)
+				if err != nil {
+					return fmt.Errorf("Error applying %s: %s", st, err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	dryRun bool
+)
+
+// step represents a single change required to reconcile the project with the manifest
+type step struct {
+	op   string
+	kind string
+	name string
+	args []string
+}
+
+// String returns the representation of a step e.g. "+ resource user"
+func (s step) String() string {
+	return fmt.Sprintf("%s %s %s", s.op, s.kind, s.name)
+}
+
+func init() {
+	ApplyCmd.Flags().BoolVarP(&dryRun, "dryRun", "d", false, "Only print the changes without applying them")
+}
+
+// plan returns the steps to reconcile the project with the manifest.
+// Removals come first, so resources can move between schemas.
+func plan(c *models.DQLConfig, s *models.ServerlessConfig, m *models.Manifest) ([]step, error) {
+	removals := []step{}
+	changes := []step{}
+	// resources with relations are applied after all other changes
+	relations := []step{}
+
+	resources, err := m.Resources(c.SingleTable)
+	if err != nil {
+		return nil, err
+	}
+
+	// resources
+	declared := m.ResourceSchemas()
+	for _, n := range helpers.SortedKeys(c.Resources) {
+		r := c.Resources[n]
+		if sn, ok := declared[n]; !ok || sn != r.Schema {
+			removals = append(removals, step{"-", "resource", n, []string{"remove", "resource", n, "-s", r.Schema}})
+		}
+	}
+
+	// schemas
+	for _, n := range helpers.SortedKeys(c.Schemas) {
+		if _, ok := m.Schemas[n]; !ok {
+			removals = append(removals, step{"-", "schema", n, []string{"remove", n}})
+		}
+	}
+	for _, n := range helpers.SortedKeys(m.Schemas) {
+		sm := m.Schemas[n]
+		args := []string{"add", "schema", n, "-p", sm.Path}
+		if sm.SingleTable {
+			args = append(args, "--single-table")
+		}
+		if cs, ok := c.Schemas[n]; !ok {
+			changes = append(changes, step{"+", "schema", n, args})
+		} else if cs.Path != sm.Path || cs.SingleTable != sm.SingleTable {
+			changes = append(changes, step{"~", "schema", n, args})
+		}
+
+		for _, rn := range helpers.SortedKeys(sm.Resources) {
+			rm := sm.Resources[rn]
+			st, related, err := resourceStep(c, resources, n, rn, rm, c.SingleTable || sm.SingleTable)
+			if err != nil {
+				return nil, err
+			}
+			if st == nil {
+				continue
+			}
+			if !related {
+				changes = append(changes, *st)
+				continue
+			}
+			if st.op == "+" {
+				// the related resources might not exist yet, so the resource is added without its relations first
+				changes = append(changes, step{"+", "resource", st.name, resourceArgs(n, rn, rm.Plain(resources))})
+				st.op = "~"
+			}
+			relations = append(relations, *st)
+		}
+	}
+
+	// functions are all serverless functions which are not schemas
+	for _, n := range helpers.SortedKeys(s.Functions) {
+		if _, ok := c.Schemas[n]; ok {
+			continue
+		}
+		if _, ok := m.Functions[n]; !ok {
+			removals = append(removals, step{"-", "function", n, []string{"remove", n}})
+		}
+	}
+	for _, n := range helpers.SortedKeys(m.Functions) {
+		f := m.Functions[n]
+		args := []string{"add", "function", n, "-p", f.Path, "-m", f.Method}
+		sf, ok := s.Functions[n]
+		if !ok {
+			changes = append(changes, step{"+", "function", n, args})
+			continue
+		}
+
+		// a function without an HTTP event is added again
+		var ev *models.HTTPEvent
+		if len(sf.Events) > 0 {
+			ev = sf.Events[0].HTTP
+		}
+		if ev == nil || ev.Path != f.Path || !strings.EqualFold(ev.Method, f.Method) {
+			changes = append(changes, step{"~", "function", n, args})
+		}
+	}
+
+	return append(append(removals, changes...), relations...), nil
+}
+
+// resourceStep returns the step to add or update the resource or nil if it is up to date.
+// The relations of the resource are checked against the resources of the manifest, related indicates whether it has any.
+func resourceStep(c *models.DQLConfig, resources map[string]*models.Resource, schema, name string, r *models.ResourceManifest, singleTable bool) (*step, bool, error) {
+	m, err := r.Model(name, schema, singleTable, resources)
+	if err != nil {
+		return nil, false, fmt.Errorf("Resource %s: %s", name, err)
+	}
+	related := len(m.Relations) > 0
+
+	args := resourceArgs(schema, name, r)
+	cr, ok := c.Resources[m.Name]
+	if !ok || cr.Schema != schema {
+		return &step{"+", "resource", m.Name, args}, related, nil
+	}
+
+	stored, err := models.ReadModel(c.ProjectPath, m.Name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, err
+	}
+	if stored != nil && stored.Equals(*m) {
+		return nil, false, nil
+	}
+
+	return &step{"~", "resource", m.Name, args}, related, nil
+}
+
+// resourceArgs returns the arguments of the add resource command for the resource
+func resourceArgs(schema, name string, r *models.ResourceManifest) []string {
+	args := []string{
+		"add", "resource", name,
+		"-s", schema,
+		"-a", r.AttributeString(),
+		"-k", r.KeySchema,
+		"-b", r.Billing,
+		"-r", strconv.FormatInt(r.Capacity["read"], 10),
+		"-w", strconv.FormatInt(r.Capacity["write"], 10),
+	}
+	gsi, lsi := r.IndexDefinitions()
+	for _, i := range gsi {
+		args = append(args, "--gsi", i)
+	}
+	for _, i := range lsi {
+		args = append(args, "--lsi", i)
+	}
+	for _, h := range r.HasOne {
+		args = append(args, "--has-one", h)
+	}
+	for _, h := range r.HasMany {
+		args = append(args, "--has-many", h)
+	}
+	if r.Versioned {
+		args = append(args, "--versioned")
+	}
+	if r.ConsistentRead {
+		args = append(args, "--consistent-read")
+	}
+	if len(r.PK) > 0 {
+		args = append(args, "--pk", r.PK)
+	}
+	if len(r.SK) > 0 {
+		args = append(args, "--sk", r.SK)
+	}
+
+	return args
+}
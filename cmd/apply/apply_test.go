@@ -0,0 +1,177 @@
+package apply_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crolly/dynQL/cmd"
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/crolly/dynQL/cmd/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	manifest = `schemas:
+  api:
+    resources:
+      user:
+        attributes: [id, name, "address:{street,zip}"]
+      event:
+        attributes: [room, ts:int64, title]
+        keySchema: room:HASH,ts:RANGE
+        billing: ondemand
+        consistentRead: true
+functions:
+  register:
+    path: /register
+    method: post
+`
+
+	// the course references the teacher, which is declared after it, and the teacher relates back to the course
+	relationsManifest = `schemas:
+  api:
+    resources:
+      course:
+        attributes: [id, name, teacher_id:ref(Teacher)]
+        gsi:
+          - name: teacher
+            keySchema: teacher_id:HASH
+      teacher:
+        attributes: [id, name, favorite_course_id:ref(Course)]
+        hasMany: [courses:Course:teacher_id]
+`
+
+	updatedManifest = `schemas:
+  api:
+    resources:
+      user:
+        attributes: [id, name, email]
+`
+)
+
+func TestApplyCmd(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer os.RemoveAll(folder)
+	assert.NoError(t, err)
+
+	// apply initial manifest
+	err = ioutil.WriteFile(filepath.Join(folder, models.ManifestFile), []byte(manifest), 0644)
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "apply")
+	assert.NoError(t, err)
+
+	c, err := models.ReadDQLConfig()
+	assert.NoError(t, err)
+	assert.Contains(t, c.Resources, "user")
+	assert.Contains(t, c.Resources, "event")
+	assert.Equal(t, "api", c.Resources["event"].Schema)
+	assert.FileExists(t, filepath.Join(folder, "models", "event.json"))
+	event, err := models.ReadModel(folder, "event")
+	assert.NoError(t, err)
+	assert.True(t, event.ConsistentRead)
+	assert.FileExists(t, filepath.Join(folder, "handler", "api", "schema", "user.go"))
+	assert.FileExists(t, filepath.Join(folder, "handler", "register", "main.go"))
+
+	// apply updated manifest
+	err = ioutil.WriteFile(filepath.Join(folder, models.ManifestFile), []byte(updatedManifest), 0644)
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "apply")
+	assert.NoError(t, err)
+
+	c, err = models.ReadDQLConfig()
+	assert.NoError(t, err)
+	assert.NotContains(t, c.Resources, "event")
+	_, err = os.Stat(filepath.Join(folder, "models", "event.go"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(folder, "handler", "register"))
+	assert.True(t, os.IsNotExist(err))
+
+	m, err := models.ReadModel(folder, "user")
+	assert.NoError(t, err)
+	assert.Contains(t, m.Attributes, "email")
+}
+
+func TestApplyCmdRelations(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer os.RemoveAll(folder)
+	assert.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(folder, models.ManifestFile), []byte(relationsManifest), 0644)
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "apply")
+	assert.NoError(t, err)
+
+	course, err := models.ReadModel(folder, "course")
+	assert.NoError(t, err)
+	if assert.Len(t, course.Relations, 1) {
+		assert.Equal(t, "teacher", course.Relations[0].Name)
+	}
+	assert.Equal(t, "string", course.Attributes["teacher_id"].GoType)
+
+	teacher, err := models.ReadModel(folder, "teacher")
+	assert.NoError(t, err)
+	if assert.Len(t, teacher.Relations, 2) {
+		assert.Equal(t, "favorite_course", teacher.Relations[0].Name)
+		assert.Equal(t, "courses", teacher.Relations[1].Name)
+		assert.Equal(t, "teacher", teacher.Relations[1].Index)
+	}
+
+	data, err := helpers.ReadDataFromFile(filepath.Join(folder, "handler", "api", "schema", "teacher.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"courses"`)
+}
+
+func TestApplyCmdEmptyEntries(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer os.RemoveAll(folder)
+	assert.NoError(t, err)
+
+	// empty entries are rejected instead of applied
+	for _, m := range []string{
+		"schemas:\n  api:\n    resources:\n      tag:\n",
+		"schemas:\n  api:\nfunctions:\n  hook:\n",
+	} {
+		err = ioutil.WriteFile(filepath.Join(folder, models.ManifestFile), []byte(m), 0644)
+		assert.NoError(t, err)
+		_, err = helpers.ExecuteCommand(cmd.RootCmd, "apply")
+		assert.Error(t, err)
+	}
+
+	// a function without events is added again
+	err = ioutil.WriteFile(filepath.Join(folder, models.ManifestFile), []byte(manifest), 0644)
+	assert.NoError(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "apply")
+	assert.NoError(t, err)
+
+	c, err := models.ReadDQLConfig()
+	assert.NoError(t, err)
+	s, err := c.ReadServerlessConfig()
+	assert.NoError(t, err)
+	s.Functions["register"].Events = nil
+	assert.NoError(t, s.Write())
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "apply")
+	assert.NoError(t, err)
+	s, err = c.ReadServerlessConfig()
+	assert.NoError(t, err)
+	if assert.Len(t, s.Functions["register"].Events, 1) {
+		assert.Equal(t, "register", s.Functions["register"].Events[0].HTTP.Path)
+	}
+}
@@ -22,10 +22,10 @@ package create
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 
 	"github.com/crolly/dynQL/cmd/helpers"
@@ -41,6 +41,9 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p, err := createProjectStructure(args[0], force)
+			if err != nil {
+				return err
+			}
 			err = os.Chdir(p)
 			if err != nil {
 				return err
@@ -50,12 +53,25 @@ var (
 		},
 	}
 
-	region, schema string
-	force          bool
+	region, schema, module string
+	force, singleTable     bool
+
+	gomod = `module %s
+
+go 1.12
 
-	gopkg = `[[constraint]]
-	name = "github.com/aws/aws-lambda-go"
-	version = "^1.0.1"`
+require (
+	github.com/aws/aws-lambda-go v1.13.2
+	github.com/aws/aws-sdk-go v1.25.0
+	github.com/gobuffalo/flect v0.1.6
+	github.com/gofrs/uuid v3.2.0+incompatible
+	github.com/graphql-go/graphql v0.7.8
+	github.com/graphql-go/handler v0.2.3
+	github.com/guregu/dynamo v1.4.1
+	github.com/mitchellh/mapstructure v1.1.2
+	github.com/stretchr/testify v1.4.0
+)
+`
 
 	gitignore = `# dynQL
 	bin/
@@ -69,10 +85,12 @@ func init() {
 	})
 	CreateCmd.Flags().StringVarP(&region, "region", "r", "eu-central-1", "Region the Project will be deployed to (e.g. us-east-1 or eu-central-1)")
 	CreateCmd.Flags().StringVarP(&schema, "schema", "s", "graphql", "Schema generated together with the create command to save one step")
+	CreateCmd.Flags().StringVarP(&module, "module", "m", "", "Go Module Path of the Project (defaults to the given project name e.g. github.com/crolly/dynQL-example)")
 	CreateCmd.Flags().BoolVarP(&force, "force", "f", false, "Force overwrite of the Directory in case it exists already")
+	CreateCmd.Flags().BoolVar(&singleTable, "single-table", false, "Store all resources of the Project in one shared DynamoDB table (single-table design)")
 }
 
-// createsProjectStructure creates the project structure with serverless.yml and mug.config.json
+// createsProjectStructure creates the project structure with go.mod and dql.conf.json
 func createProjectStructure(projectName string, force bool) (string, error) {
 	// create new config from project name
 	config, err := newConfig(projectName)
@@ -80,17 +98,17 @@ func createProjectStructure(projectName string, force bool) (string, error) {
 		return "", err
 	}
 
-	projPath := helpers.GetProjectPath(config.ProjectPath)
+	projPath := config.ProjectPath
 	if force {
 		os.RemoveAll(projPath)
 	} else if _, err := os.Stat(projPath); !os.IsNotExist(err) {
 		// projectPath exists already
-		return "", errors.New("folder already exists - raising error: " + err.Error())
+		return "", errors.New("folder already exists: " + projPath)
 	}
 	os.MkdirAll(projPath, 0755)
 
-	// write Gopkg.toml
-	if err := ioutil.WriteFile(filepath.Join(projPath, "Gopkg.toml"), []byte(gopkg), 0644); err != nil {
+	// write go.mod
+	if err := ioutil.WriteFile(filepath.Join(projPath, "go.mod"), []byte(fmt.Sprintf(gomod, config.ModulePath)), 0644); err != nil {
 		return "", err
 	}
 
@@ -104,61 +122,27 @@ func createProjectStructure(projectName string, force bool) (string, error) {
 }
 
 func newConfig(projectName string) (*models.DQLConfig, error) {
-	pName, path, err := getPath(projectName)
+	wd, err := helpers.GetWorkingDir()
 	if err != nil {
 		return nil, err
 	}
 
-	config := &models.DQLConfig{
-		ProjectName: pName,
-		ProjectPath: path,
-		Region:      region,
-	}
-
-	return config, nil
-}
-
-func getPath(projectName string) (string, string, error) {
-	path := ""
-
-	// environments GOPATH
-	goPath := os.Getenv("GOPATH")
-	if len(goPath) == 0 {
-		return "", "", errors.New("$GOPATH is not set")
+	modulePath := module
+	if len(modulePath) == 0 {
+		modulePath = projectName
 	}
-	srcPath := filepath.Join(goPath, "src")
-
-	if strings.Contains(projectName, "/") {
-		// project is created with full path to GOPATH src e.g. github.com/crolly/dynQL-example
-		path = projectName
-
-		i := strings.LastIndex(projectName, "/")
-		projectName = projectName[i+1 : len(projectName)]
-	} else {
-		// project is created with project name only
-		wd, err := helpers.GetWorkingDir()
-		if err != nil {
-			return "", "", err
-		}
-		if filepathHasPrefix(wd, srcPath) {
-			path = filepath.Join(wd, projectName)
-			path = strings.TrimPrefix(strings.Replace(path, srcPath, "", 1), "/")
-		} else {
-			return "", "", errors.New("You must either create the project inside of $GOPATH or provide the full path (e.g. github.com/crolly/dynQL-example")
-		}
+	if i := strings.LastIndex(projectName, "/"); i >= 0 {
+		// project is created with full module path e.g. github.com/crolly/dynQL-example
+		projectName = projectName[i+1:]
 	}
 
-	return projectName, path, nil
-}
-
-func filepathHasPrefix(path string, prefix string) bool {
-	if len(path) <= len(prefix) {
-		return false
-	}
-	if runtime.GOOS == "windows" {
-		// Paths in windows are case-insensitive.
-		return strings.EqualFold(path[0:len(prefix)], prefix)
+	config := &models.DQLConfig{
+		ProjectName: projectName,
+		ProjectPath: filepath.Join(wd, projectName),
+		ModulePath:  modulePath,
+		Region:      region,
+		SingleTable: singleTable,
 	}
-	return path[0:len(prefix)] == prefix
 
+	return config, nil
 }
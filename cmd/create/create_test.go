@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 
 	"github.com/crolly/dynQL/cmd/models"
@@ -16,25 +15,64 @@ import (
 )
 
 func TestCreateCommand(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
 	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test")
 	assert.NoError(t, err)
+	os.Chdir(wd)
 
-	wd, _ := helpers.GetWorkingDir()
 	folder := filepath.Join(wd, "test")
 	assert.DirExists(t, folder)
 	assert.FileExists(t, filepath.Join(folder, "dql.conf.json"))
-	assert.FileExists(t, filepath.Join(folder, "Gopkg.toml"))
+	assert.FileExists(t, filepath.Join(folder, "go.mod"))
+
+	mod, _ := helpers.ReadDataFromFile(filepath.Join(folder, "go.mod"))
+	assert.Contains(t, string(mod), "module test")
+	assert.Contains(t, string(mod), "github.com/guregu/dynamo")
 
 	data, _ := helpers.ReadDataFromFile(filepath.Join(folder, "dql.conf.json"))
 
 	var actual models.DQLConfig
 	json.Unmarshal(data, &actual)
 
-	si := strings.Index(folder, "github.com")
 	expected := models.DQLConfig{
 		ProjectName: "test",
-		ProjectPath: folder[si:],
+		ModulePath:  "test",
+		Region:      "eu-central-1",
+		Schemas: map[string]*models.Schema{
+			"graphql": {Name: "graphql", Path: "graphql"},
+		},
+		Resources: map[string]*models.Resource{},
+	}
+	assert.Equal(t, expected, actual)
+
+	os.RemoveAll(folder)
+}
+
+func TestCreateCommandWithModulePath(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "github.com/crolly/test")
+	assert.NoError(t, err)
+	os.Chdir(wd)
+
+	folder := filepath.Join(wd, "test")
+	assert.DirExists(t, folder)
+
+	data, _ := helpers.ReadDataFromFile(filepath.Join(folder, "go.mod"))
+	assert.Contains(t, string(data), "module github.com/crolly/test")
+
+	data, _ = helpers.ReadDataFromFile(filepath.Join(folder, "dql.conf.json"))
+
+	var actual models.DQLConfig
+	json.Unmarshal(data, &actual)
+
+	expected := models.DQLConfig{
+		ProjectName: "test",
+		ModulePath:  "github.com/crolly/test",
 		Region:      "eu-central-1",
+		Schemas: map[string]*models.Schema{
+			"graphql": {Name: "graphql", Path: "graphql"},
+		},
+		Resources: map[string]*models.Resource{},
 	}
 	assert.Equal(t, expected, actual)
 
@@ -0,0 +1,90 @@
+// Copyright © 2019 Christian Rolly <mail@chromium-solutions.de>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package data
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/crolly/dynQL/cmd/models"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the data export command
+var (
+	exportCmd = &cobra.Command{
+		Use:   "export resource [flags]",
+		Short: "Export the items of a resource from the local DynamoDB as JSON lines",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := models.ReadDQLConfig()
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if len(output) > 0 {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			// an interrupt cancels the scan of all segments
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			defer signal.Stop(interrupt)
+			go func() {
+				select {
+				case <-interrupt:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			n, err := c.ExportResource(ctx, w, args[0], mode, segments)
+			if err != nil {
+				return err
+			}
+			if len(output) > 0 {
+				log.Printf("Exported %d items of %s to %s", n, args[0], output)
+			}
+
+			return nil
+		},
+	}
+
+	output, mode string
+	segments     int
+)
+
+func init() {
+	DataCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "File the items are written to (defaults to stdout)")
+	exportCmd.Flags().StringVarP(&mode, "mode", "m", "debug", "Mode of the local table, e.g. 'debug' or 'test'")
+	exportCmd.Flags().IntVarP(&segments, "segments", "n", 4, "Number of segments scanned in parallel")
+}
@@ -0,0 +1,74 @@
+package data_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crolly/dynQL/cmd"
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCmd(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer os.RemoveAll(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "post", "-s", "api", "-a", "id,views:int64", "-k", "id:HASH")
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "data", "export", "comment")
+	assert.Error(t, err)
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "data", "export", "post", "-n", "0")
+	assert.Error(t, err)
+
+	// fake the local DynamoDB, each segment holds one item
+	l, err := net.Listen("tcp", "localhost:8000")
+	if err != nil {
+		t.Skip("the port of the local DynamoDB is in use")
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"TableName":"test-posts-debug"`)
+		assert.Contains(t, string(body), `"TotalSegments":3`)
+		segment := strings.Split(strings.Split(string(body), `"Segment":`)[1], ",")[0]
+		fmt.Fprintf(w, `{"Items":[{"id":{"S":"%s"},"views":{"N":"9007199254740993"}}]}`, segment)
+	}))
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.Start()
+	defer srv.Close()
+	for _, k := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		if v, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, v)
+		} else {
+			defer os.Unsetenv(k)
+		}
+		os.Setenv(k, "test")
+	}
+
+	out := filepath.Join(folder, "posts.json")
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "data", "export", "post", "-n", "3", "-o", out)
+	assert.NoError(t, err)
+
+	data, err := helpers.ReadDataFromFile(out)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.ElementsMatch(t, []string{
+		`{"id":"0","views":9007199254740993}`,
+		`{"id":"1","views":9007199254740993}`,
+		`{"id":"2","views":9007199254740993}`,
+	}, lines)
+}
@@ -44,6 +44,12 @@ var (
 				return err
 			}
 
+			// run everything from the project root
+			err = os.Chdir(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+
 			// set debug environment
 			os.Setenv("GRAPH_DYNAMO_MODE", "debug")
 
@@ -96,7 +102,7 @@ func startLocalAPI(region string) {
 		log.Printf("Starting local API at port %s with debugger at %s...\n", gwPort, debugPort)
 	}
 
-	env := []string{"LOCAL=TRUE", "ENPOINT=http://dynamodb:8000", "REGION=" + region}
+	env := []string{"LOCAL=TRUE", "ENDPOINT=http://dynamodb:8000", "REGION=" + region}
 	helpers.RunCmdWithEnv(env, "sam", args...)
 }
 
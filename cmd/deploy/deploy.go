@@ -40,6 +40,12 @@ var (
 				return err
 			}
 
+			// run everything from the project root
+			err = os.Chdir(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+
 			if test {
 				os.Setenv("GRAPH_DYNAMO_MODE", "test")
 				// create lambda-local network if it doesn't exist already
@@ -42,6 +42,12 @@ var (
 				return err
 			}
 
+			// run everything from the project root
+			err = os.Chdir(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+
 			// set debug environment
 			os.Setenv("GRAPH_DYNAMO_MODE", "debug")
 
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -30,6 +31,18 @@ var (
 	RemoveBox = packr.New("remove", "../../templates/remove")
 )
 
+const (
+	// ConfigFile is the name of the dynQL configuration file marking the project root
+	ConfigFile = "dql.conf.json"
+	// CustomRegionBegin marks the beginning of a region in a generated file that is preserved on regeneration.
+	// A region is a line "// dynql:custom begin <name>" followed by the custom code and closed by "// dynql:custom end <name>".
+	// When the file is rendered again, the code of each region replaces the default content of the region with the same name,
+	// a region which is not closed or not rendered anymore aborts the rendering instead of losing its code.
+	CustomRegionBegin = "// dynql:custom begin "
+	// CustomRegionEnd marks the end of a region in a generated file that is preserved on regeneration
+	CustomRegionEnd = "// dynql:custom end "
+)
+
 // GetWorkingDir get the directory the current command is run out of
 func GetWorkingDir() (string, error) {
 	wd, err := os.Getwd()
@@ -40,21 +53,31 @@ func GetWorkingDir() (string, error) {
 	return wd, err
 }
 
-// GetProjectPath gets the OS path of the project
-func GetProjectPath(path string) string {
-	goPath := os.Getenv("GOPATH")
-	if len(goPath) == 0 {
-		log.Fatal("$GOPATH is not set")
+// GetProjectRoot walks up from the working directory to the folder containing the dql.conf.json
+func GetProjectRoot() (string, error) {
+	wd, err := GetWorkingDir()
+	if err != nil {
+		return "", err
 	}
-	srcPath := filepath.Join(goPath, "src")
 
-	return filepath.Join(srcPath, path)
+	for dir := wd; ; {
+		if _, err := os.Stat(filepath.Join(dir, ConfigFile)); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// reached the file system root
+			return "", fmt.Errorf("%s not found in %s or any of its parent directories", ConfigFile, wd)
+		}
+		dir = parent
+	}
 }
 
 // AwsType returns the AWS datatype for a given golang type
 func AwsType(s string) string {
 	switch strings.ToLower(s) {
-	case "string", "time.Time", "*time.Time", "uuid.UUID":
+	case "string", "time.time", "*time.time", "uuid.uuid":
 		return "S"
 	case "[]string":
 		return "SS"
@@ -69,7 +92,7 @@ func AwsType(s string) string {
 		return "NS"
 	case "map[string]string", "map[string]int", "map[string]interface{}":
 		return "M"
-	case "[]byte":
+	case "[]byte", "json.rawmessage":
 		return "B"
 	case "[][]byte":
 		return "BS"
@@ -97,16 +120,6 @@ func LoadTemplateFromBox(b *packr.Box, file string) (*template.Template, error)
 		"Underscore": func(s string) string {
 			return flect.Underscore(s)
 		},
-		"UnderscoreList": func(as interface{}) string {
-			s := ""
-			v := reflect.ValueOf(as)
-			iter := v.MapRange()
-			for iter.Next() {
-				ident := iter.Value().FieldByName("Ident").Interface().(flect.Ident)
-				s += "\"" + ident.Underscore().String() + "\"" + ","
-			}
-			return strings.TrimSuffix(s, ",")
-		},
 		"Camelize": func(s string) string {
 			return flect.Camelize(s)
 		},
@@ -188,6 +201,38 @@ func GetFuncName(resourceName, functionName string) string {
 
 }
 
+// SortedKeys returns the sorted keys of a map with string keys
+func SortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SplitList splits a comma separated list at the commas outside of parentheses, e.g. status:enum(DRAFT,PUBLISHED),title
+func SplitList(s string) []string {
+	parts := []string{}
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
 // Contains checks whether a string slice contains a given string
 func Contains(s []string, v string) bool {
 	for _, e := range s {
@@ -274,13 +319,23 @@ func StartLocalDynamoDB() error {
 	return nil
 }
 
-// RenderFile renders a template to the file at folder/fName with the given data
+// RenderFile renders a template to the file at folder/fName with the given data.
+// The content of custom regions in an existing file is preserved. If a region of the existing file is not closed
+// or has no place in the rendered output, an error is returned and the file is left untouched.
 func RenderFile(box *packr.Box, fName, tPath, folder string, data map[string]interface{}) error {
-	f, err := os.Create(filepath.Join(folder, fName))
-	if err != nil {
+	fp := filepath.Join(folder, fName)
+
+	// read custom regions of the existing file
+	regions := map[string]string{}
+	existing, err := ioutil.ReadFile(fp)
+	if err == nil {
+		regions, err = customRegions(existing)
+		if err != nil {
+			return fmt.Errorf("%s: %s", fp, err)
+		}
+	} else if !os.IsNotExist(err) {
 		return err
 	}
-	defer f.Close()
 
 	// load template
 	tmpl, err := LoadTemplateFromBox(box, tPath)
@@ -288,12 +343,88 @@ func RenderFile(box *packr.Box, fName, tPath, folder string, data map[string]int
 		return err
 	}
 
-	err = tmpl.Execute(f, data)
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	out, err := mergeCustomRegions(buf.Bytes(), regions)
+	if err != nil {
+		return fmt.Errorf("%s: %s", fp, err)
+	}
+
+	return ioutil.WriteFile(fp, out, 0644)
+}
+
+// customRegions returns the content of all custom regions keyed by the region name.
+// Every region has to be closed by the end marker with its name before the next region begins.
+func customRegions(data []byte) (map[string]string, error) {
+	regions := map[string]string{}
+
+	name := ""
+	var content []string
+	for _, l := range strings.SplitAfter(string(data), "\n") {
+		t := strings.TrimSpace(l)
+		switch {
+		case strings.HasPrefix(t, CustomRegionBegin):
+			if len(name) > 0 {
+				return nil, fmt.Errorf("Custom region %s is not closed by %s%s", name, CustomRegionEnd, name)
+			}
+			name = strings.TrimSpace(strings.TrimPrefix(t, CustomRegionBegin))
+			content = nil
+		case len(name) > 0 && t == CustomRegionEnd+name:
+			regions[name] = strings.Join(content, "")
+			name = ""
+		case strings.HasPrefix(t, strings.TrimSpace(CustomRegionEnd)):
+			return nil, fmt.Errorf("%s does not close an open custom region", t)
+		case len(name) > 0:
+			content = append(content, l)
+		}
+	}
+	if len(name) > 0 {
+		return nil, fmt.Errorf("Custom region %s is not closed by %s%s", name, CustomRegionEnd, name)
+	}
+
+	return regions, nil
+}
+
+// mergeCustomRegions replaces the content of the custom regions in the rendered output with the preserved content.
+// A preserved region holding code which the rendered output does not contain anymore is an error.
+func mergeCustomRegions(out []byte, regions map[string]string) ([]byte, error) {
+	if len(regions) == 0 {
+		return out, nil
+	}
+
+	var sb strings.Builder
+	name := ""
+	for _, l := range strings.SplitAfter(string(out), "\n") {
+		t := strings.TrimSpace(l)
+		switch {
+		case len(name) == 0 && strings.HasPrefix(t, CustomRegionBegin):
+			sb.WriteString(l)
+			n := strings.TrimSpace(strings.TrimPrefix(t, CustomRegionBegin))
+			if c, ok := regions[n]; ok {
+				// skip the rendered default content of the region
+				name = n
+				sb.WriteString(c)
+				delete(regions, n)
+			}
+		case len(name) > 0 && t == CustomRegionEnd+name:
+			sb.WriteString(l)
+			name = ""
+		case len(name) == 0:
+			sb.WriteString(l)
+		}
+	}
+
+	for _, n := range SortedKeys(regions) {
+		if len(strings.TrimSpace(regions[n])) > 0 {
+			return nil, fmt.Errorf("Custom region %s does not exist anymore, move its code before regenerating", n)
+		}
+	}
+
+	return []byte(sb.String()), nil
 }
 
 // ExecuteCommand executes a command from anywhere
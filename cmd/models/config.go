@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -23,23 +27,30 @@ import (
 
 // DQLConfig ...
 type DQLConfig struct {
-	ProjectName string
-	ProjectPath string
-	Region      string
-	Schemas     map[string]*Schema
-	Resources   map[string]*Resource
+	ProjectName  string
+	ProjectPath  string `json:"-"`
+	ModulePath   string
+	Region       string
+	SingleTable  bool `json:",omitempty"`
+	Schemas      map[string]*Schema
+	Resources    map[string]*Resource
+	Dependencies []string `json:",omitempty"`
 }
 
 // Schema ...
 type Schema struct {
-	Name string
-	Path string
+	Name        string
+	Path        string
+	SingleTable bool `json:",omitempty"`
 }
 
 // Resource ...
 type Resource struct {
 	Ident      flect.Ident
+	Schema     string `json:",omitempty"`
+	KeySchema  map[string]string
 	Attributes map[string]AttributeDefinition
+	Indexes    map[string]map[string]string `json:",omitempty"`
 }
 
 // AttributeDefinition represents the definition of a resource's attribute
@@ -50,17 +61,18 @@ type AttributeDefinition struct {
 
 // ReadDQLConfig ...
 func ReadDQLConfig() (*DQLConfig, error) {
-	wd, err := helpers.GetWorkingDir()
+	root, err := helpers.GetProjectRoot()
 	if err != nil {
 		return nil, err
 	}
-	data, err := helpers.ReadDataFromFile(filepath.Join(wd, "dql.conf.json"))
+	data, err := helpers.ReadDataFromFile(filepath.Join(root, helpers.ConfigFile))
 	if err != nil {
 		return nil, err
 	}
 
 	var config DQLConfig
 	json.Unmarshal(data, &config)
+	config.ProjectPath = root
 
 	// make sure map exists
 	if len(config.Resources) == 0 {
@@ -72,7 +84,7 @@ func ReadDQLConfig() (*DQLConfig, error) {
 
 // Write write the DQLConfig to dql.config.json in the project path
 func (c DQLConfig) Write() error {
-	f := filepath.Join(helpers.GetProjectPath(c.ProjectPath), "dql.conf.json")
+	f := filepath.Join(c.ProjectPath, helpers.ConfigFile)
 
 	json, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -86,7 +98,7 @@ func (c DQLConfig) newServerlessConfig() ServerlessConfig {
 	s := newDefaultServerlessConfig()
 	s.Service = Service{Name: c.ProjectName}
 	s.Provider.Region = c.Region
-	s.ProjectPath = helpers.GetProjectPath(c.ProjectPath)
+	s.ProjectPath = c.ProjectPath
 
 	return s
 }
@@ -95,11 +107,12 @@ func (c DQLConfig) newServerlessConfig() ServerlessConfig {
 // If a serverless.yml file does not exist, a new default ServerlessConfig is returned
 func (c DQLConfig) ReadServerlessConfig() (*ServerlessConfig, error) {
 	var sc ServerlessConfig
-	data, err := helpers.ReadDataFromFile(filepath.Join(helpers.GetProjectPath(c.ProjectPath), "serverless.yml"))
+	data, err := helpers.ReadDataFromFile(filepath.Join(c.ProjectPath, "serverless.yml"))
 	if err == nil {
 		if err := yaml.Unmarshal(data, &sc); err != nil {
 			return nil, err
 		}
+		sc.ProjectPath = c.ProjectPath
 	} else if os.IsNotExist(err) {
 		// file doesn't exist return default ServerlessConfig
 		sc = c.newServerlessConfig()
@@ -143,13 +156,19 @@ func (c *DQLConfig) Remove(name string) error {
 	return s.RemoveFunction(name).Write()
 }
 
-// RemoveResource removes a given resource from the DQLConfig and ServerlessConfig
+// RemoveResource removes a given resource from the DQLConfig and ServerlessConfig.
+// A shared table is kept as long as other resources are stored in it
 func (c *DQLConfig) RemoveResource(resourceName string, deleteTable bool) error {
+	var table string
+	if r, ok := c.Resources[resourceName]; ok {
+		table = c.SharedTable(r.Schema)
+	}
+
 	// remove from DQLConfig
 	delete(c.Resources, resourceName)
 
 	// delete table
-	if deleteTable {
+	if deleteTable && len(table) == 0 {
 		svc := c.connectDB()
 		result, err := svc.ListTables(&dynamodb.ListTablesInput{})
 		if err != nil {
@@ -167,8 +186,15 @@ func (c *DQLConfig) RemoveResource(resourceName string, deleteTable bool) error
 	if err != nil {
 		return err
 	}
+	s.removeResource(resourceName)
+	if len(table) > 0 {
+		err = s.setSharedTable(c, table, nil)
+		if err != nil {
+			return err
+		}
+	}
 
-	return s.removeResource(resourceName).Write()
+	return s.Write()
 }
 
 func (c DQLConfig) connectDB() *dynamodb.DynamoDB {
@@ -199,11 +225,20 @@ func (c DQLConfig) CreateResourceTables(overwrite bool) error {
 	if err != nil {
 		return err
 	}
+	mode := os.Getenv("GRAPH_DYNAMO_MODE")
+	created := map[string]bool{}
 	for _, r := range c.Resources {
-		mode := os.Getenv("GRAPH_DYNAMO_MODE")
-		tableName := c.ProjectName + "-" + r.Ident.Pluralize().Camelize().String() + "-" + mode
+		tableName := c.LocalTableName(r, mode)
+		// resources in single-table mode share their table
+		if created[tableName] {
+			continue
+		}
+		created[tableName] = true
 
 		rName := r.Ident.Pascalize().String() + "DynamoDbTable"
+		if table := c.SharedTable(r.Schema); len(table) > 0 {
+			rName = sharedTableResource(table)
+		}
 		res := s.Resources.Resources[rName]
 		if res == nil {
 			return fmt.Errorf("Resource %s not valid. Please check your serverless.yml", rName)
@@ -272,13 +307,12 @@ func createTableForResource(svc *dynamodb.DynamoDB, tableName string, props Prop
 		}
 	}
 
-	// get the local secondary indexex
-	// TODO: support for non index attributes
+	// get the local secondary indexes
 	lsi := []*dynamodb.LocalSecondaryIndex{}
 	for _, i := range props.LocalSecondaryIndexes {
 		keySchema := []*dynamodb.KeySchemaElement{}
 		for _, k := range i.KeySchema {
-			if !existsInAttributes(k.AttributeName, attributes) {
+			if !existsInAttributes(flect.New(k.AttributeName).Underscore().String(), attributes) {
 				return fmt.Errorf("%s does not exist in the AttributeDefinitions. Please check your serverless.yml", k.AttributeName)
 			}
 			keySchema = append(keySchema, &dynamodb.KeySchemaElement{
@@ -287,11 +321,9 @@ func createTableForResource(svc *dynamodb.DynamoDB, tableName string, props Prop
 			})
 		}
 		lsi = append(lsi, &dynamodb.LocalSecondaryIndex{
-			IndexName: aws.String(i.IndexName),
-			KeySchema: keySchema,
-			Projection: &dynamodb.Projection{
-				ProjectionType: aws.String(i.Projection.ProjectionType),
-			},
+			IndexName:  aws.String(i.IndexName),
+			KeySchema:  keySchema,
+			Projection: newProjection(i.Projection),
 		})
 	}
 
@@ -299,7 +331,7 @@ func createTableForResource(svc *dynamodb.DynamoDB, tableName string, props Prop
 	for _, i := range props.GlobalSecondaryIndexes {
 		keySchema := []*dynamodb.KeySchemaElement{}
 		for _, k := range i.KeySchema {
-			if !existsInAttributes(k.AttributeName, attributes) {
+			if !existsInAttributes(flect.New(k.AttributeName).Underscore().String(), attributes) {
 				return fmt.Errorf("%s does not exist in the AttributeDefinitions. Please check your serverless.yml", k.AttributeName)
 			}
 			keySchema = append(keySchema, &dynamodb.KeySchemaElement{
@@ -308,11 +340,9 @@ func createTableForResource(svc *dynamodb.DynamoDB, tableName string, props Prop
 			})
 		}
 		idx := &dynamodb.GlobalSecondaryIndex{
-			IndexName: aws.String(i.IndexName),
-			KeySchema: keySchema,
-			Projection: &dynamodb.Projection{
-				ProjectionType: aws.String(i.Projection.ProjectionType),
-			},
+			IndexName:  aws.String(i.IndexName),
+			KeySchema:  keySchema,
+			Projection: newProjection(i.Projection),
 		}
 		if i.ProvisionedThroughput != nil {
 			idx.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
@@ -356,6 +386,17 @@ func createTableForResource(svc *dynamodb.DynamoDB, tableName string, props Prop
 	return nil
 }
 
+func newProjection(p Projection) *dynamodb.Projection {
+	projection := &dynamodb.Projection{
+		ProjectionType: aws.String(p.ProjectionType),
+	}
+	if len(p.NonKeyAttributes) > 0 {
+		projection.NonKeyAttributes = aws.StringSlice(p.NonKeyAttributes)
+	}
+
+	return projection
+}
+
 func existsInAttributes(attributeName string, attributes []*dynamodb.AttributeDefinition) bool {
 	for _, attributeDefinition := range attributes {
 		if *attributeDefinition.AttributeName == attributeName {
@@ -379,7 +420,7 @@ func (c DQLConfig) deleteTable(svc *dynamodb.DynamoDB, tableName string) error {
 
 func (c DQLConfig) renderMakefile(t *template.Template) error {
 	// open file and execute template
-	f, err := os.Create(filepath.Join(helpers.GetProjectPath(c.ProjectPath), "Makefile"))
+	f, err := os.Create(filepath.Join(c.ProjectPath, "Makefile"))
 	if err != nil {
 		return err
 	}
@@ -409,8 +450,14 @@ func (c DQLConfig) make(path, target string, test bool) error {
 		return err
 	}
 
+	// update go.mod and go.sum if the imports of the project changed
+	err = c.tidy()
+	if err != nil {
+		return err
+	}
+
 	// clear the debug binaries
-	os.RemoveAll(filepath.Join(helpers.GetProjectPath(c.ProjectPath), "debug"))
+	os.RemoveAll(filepath.Join(c.ProjectPath, "debug"))
 	// render for each resource/ function group
 	c.renderMakefile(t)
 	// run test if flag indicates so
@@ -423,6 +470,62 @@ func (c DQLConfig) make(path, target string, test bool) error {
 	return nil
 }
 
+// tidy runs go mod tidy if the dependency set of the project differs from the one of the last build
+func (c DQLConfig) tidy() error {
+	deps, err := c.dependencies()
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(filepath.Join(c.ProjectPath, "go.sum"))
+	if err == nil && reflect.DeepEqual(deps, c.Dependencies) {
+		return nil
+	}
+
+	log.Println("Dependencies changed, running go mod tidy")
+	err = helpers.RunCmd("go", "mod", "tidy")
+	if err != nil {
+		return err
+	}
+
+	// remember dependency set for the next build
+	c.Dependencies = deps
+	return c.Write()
+}
+
+// dependencies returns the sorted list of all packages imported by the Go files of the project
+func (c DQLConfig) dependencies() ([]string, error) {
+	deps := []string{}
+	fset := token.NewFileSet()
+	err := filepath.Walk(c.ProjectPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "bin", "debug", "dlv", "vendor", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		for _, i := range f.Imports {
+			deps = helpers.AppendStringIfMissing(deps, strings.Trim(i.Path.Value, "\""))
+		}
+		return nil
+	})
+	sort.Strings(deps)
+
+	return deps, err
+}
+
 // MakeDebug renders the Makefile and builds the debug binaries
 func (c DQLConfig) MakeDebug() {
 	c.make("debug", "debug", false)
@@ -436,7 +539,7 @@ func (c DQLConfig) MakeBuild(test bool) {
 // RemoveFiles ...
 func (c DQLConfig) RemoveFiles(name string) error {
 	// function folder
-	folder := filepath.Join(helpers.GetProjectPath(c.ProjectPath), "handler", name)
+	folder := filepath.Join(c.ProjectPath, "handler", name)
 
 	return os.RemoveAll(folder)
 }
@@ -445,10 +548,11 @@ func (c DQLConfig) RemoveFiles(name string) error {
 func (c DQLConfig) RemoveResourceFiles(schemaName, resourceName string) error {
 	f := resourceName + ".go"
 	t := resourceName + "_test.go"
-	projPath := helpers.GetProjectPath(c.ProjectPath)
+	projPath := c.ProjectPath
 	files := []string{
 		filepath.Join(projPath, "models", f),
 		filepath.Join(projPath, "models", t),
+		filepath.Join(projPath, "models", resourceName+".json"),
 		filepath.Join(projPath, "services", f),
 		filepath.Join(projPath, "services", t),
 	}
@@ -460,7 +564,7 @@ func (c DQLConfig) RemoveResourceFiles(schemaName, resourceName string) error {
 
 	for _, file := range files {
 		err := os.Remove(file)
-		if err != nil {
+		if err != nil && !os.IsNotExist(err) {
 			return err
 		}
 	}
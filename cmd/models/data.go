@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/gobuffalo/flect"
+)
+
+// ExportResource writes the items of the resource's table in the local DynamoDB named by the given mode to w, one JSON object per line.
+// The table is scanned by the given number of parallel segments, in a shared table only the items of the resource are exported.
+// It returns the number of exported items
+func (c DQLConfig) ExportResource(ctx context.Context, w io.Writer, name, mode string, segments int) (int, error) {
+	r, ok := c.Resources[flect.Camelize(name)]
+	if !ok {
+		return 0, fmt.Errorf("Resource %s does not exist", name)
+	}
+	if segments < 1 {
+		return 0, fmt.Errorf("The number of segments must be positive")
+	}
+
+	input := dynamodb.ScanInput{
+		TableName: aws.String(c.LocalTableName(r, mode)),
+	}
+	if len(c.SharedTable(r.Schema)) > 0 {
+		m, err := ReadModel(c.ProjectPath, flect.Camelize(name))
+		if err != nil {
+			return 0, err
+		}
+		input.FilterExpression = aws.String("#type = :type")
+		input.ExpressionAttributeNames = map[string]*string{"#type": aws.String(TypeAttribute)}
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":type": {S: aws.String(m.TypeName())},
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	svc := c.connectDB()
+	e := &itemEncoder{enc: json.NewEncoder(w)}
+	errs := make(chan error, segments)
+	var wg sync.WaitGroup
+	for i := 0; i < segments; i++ {
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			err := exportSegment(ctx, svc, input, segment, segments, e)
+			if err != nil {
+				errs <- err
+				// stop the other segments
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	// the first error caused the cancellation of the other segments
+	if err := <-errs; err != nil {
+		return e.count, err
+	}
+
+	return e.count, nil
+}
+
+// exportSegment writes the items of a segment of a parallel Scan with the encoder.
+// It mirrors scanSegment in templates/resource/dynamo.tmpl, which the generated projects use and the CLI cannot import,
+// changes to the paging of the Scan have to be applied to both
+func exportSegment(ctx context.Context, svc *dynamodb.DynamoDB, input dynamodb.ScanInput, segment, segments int, e *itemEncoder) error {
+	input.Segment = aws.Int64(int64(segment))
+	input.TotalSegments = aws.Int64(int64(segments))
+
+	for {
+		output, err := svc.ScanWithContext(ctx, &input)
+		if err != nil {
+			return err
+		}
+		if err := e.encode(output.Items); err != nil {
+			return err
+		}
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// itemEncoder writes DynamoDB items as JSON objects, it is safe for concurrent use by the segments of a Scan
+type itemEncoder struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	count int
+}
+
+// encode writes the given items, numbers keep their exact representation
+func (e *itemEncoder) encode(items []map[string]*dynamodb.AttributeValue) error {
+	d := dynamodbattribute.NewDecoder(func(d *dynamodbattribute.Decoder) {
+		d.UseNumber = true
+	})
+
+	values := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		if err := d.Decode(&dynamodb.AttributeValue{M: item}, &values[i]); err != nil {
+			return err
+		}
+		jsonNumbers(values[i])
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, v := range values {
+		if err := e.enc.Encode(v); err != nil {
+			return err
+		}
+		e.count++
+	}
+
+	return nil
+}
+
+// jsonNumbers replaces the decoded DynamoDB numbers in v by JSON numbers, which are encoded without quotes
+func jsonNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case dynamodbattribute.Number:
+		return json.Number(t)
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = jsonNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = jsonNumbers(e)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/gobuffalo/flect"
+)
+
+var (
+	enumType  = regexp.MustCompile(`^enum\((.*)\)$`)
+	enumValue = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+)
+
+// parseEnum returns the values of an attribute of the type enum(VALUE,...) and nil for any other type.
+// The values have to be GraphQL names, e.g. enum(DRAFT,PUBLISHED,ARCHIVED)
+func parseEnum(goType string) ([]string, error) {
+	match := enumType.FindStringSubmatch(goType)
+	if match == nil {
+		return nil, nil
+	}
+
+	values := []string{}
+	consts := []string{}
+	for _, v := range strings.Split(match[1], ",") {
+		v = strings.TrimSpace(v)
+		if !enumValue.MatchString(v) || v == "true" || v == "false" || v == "null" {
+			return nil, fmt.Errorf("Invalid enum value %s. Enum values have to be names like DRAFT", v)
+		}
+		c := enumConstSuffix(v)
+		if helpers.Contains(consts, c) {
+			return nil, fmt.Errorf("Enum value %s is declared twice", v)
+		}
+		values = append(values, v)
+		consts = append(consts, c)
+	}
+
+	return values, nil
+}
+
+// enumConstSuffix returns the suffix of the Go constant of an enum value, e.g. Published for PUBLISHED
+func enumConstSuffix(v string) string {
+	return flect.Pascalize(strings.ToLower(v))
+}
+
+// enumTypeName returns the name of the Go type of the enum attribute with the given name, e.g. PostStatus
+func (m Model) enumTypeName(name string) string {
+	return m.Ident.Pascalize().String() + flect.Pascalize(name)
+}
+
+// enumString returns the Go type, the constants and the GraphQL Enum of an enum attribute of the model
+func (a Attribute) enumString(m Model) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("// %s is the enum of the %s of the %s\n", a.GoType, a.Ident.Pascalize(), m.Ident.Pascalize()))
+	sb.WriteString(fmt.Sprintf("type %s string\n\n", a.GoType))
+	sb.WriteString(fmt.Sprintf("// Values of the %s\nconst (\n", a.GoType))
+	consts := []string{}
+	for _, v := range a.Enum {
+		c := a.GoType + enumConstSuffix(v)
+		sb.WriteString(fmt.Sprintf("\t%s %s = \"%s\"\n", c, a.GoType, v))
+		consts = append(consts, c)
+	}
+	sb.WriteString(")\n\n")
+	sb.WriteString(fmt.Sprintf("// %sEnum is the GraphQL Enum of the %s\n", a.GoType, a.GoType))
+	sb.WriteString(fmt.Sprintf("var %sEnum = newEnum(\"%s\", \"The %s of a %s\", %s)\n", a.GoType, a.GoType, a.Ident.Humanize(), m.Ident.Pascalize(), strings.Join(consts, ", ")))
+
+	return sb.String()
+}
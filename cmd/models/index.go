@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+)
+
+// Index represents a local or global secondary index of a resource model
+type Index struct {
+	Name             string            `json:"name"`
+	Ident            flect.Ident       `json:"ident"`
+	Global           bool              `json:"global"`
+	KeySchema        map[string]string `json:"key_schema"`
+	Projection       string            `json:"projection"`
+	NonKeyAttributes []string          `json:"non_key_attributes,omitempty"`
+	CapacityUnits    map[string]int64  `json:"capacity_units,omitempty"`
+	Overload         string            `json:"overload,omitempty"`
+	PartitionKey     string            `json:"partition_key,omitempty"`
+	SortKey          string            `json:"sort_key,omitempty"`
+}
+
+// ParseIndex parses an index definition like
+// name=email;keySchema=email:HASH,created:RANGE;projection=INCLUDE;nonKey=name,age;read=2;write=2.
+// In single-table mode a global index is stored in an overloaded index of the shared table with key templates like
+// name=byEmail;keySchema=email:HASH;overload=gsi1;pk=EMAIL#{email};sk=USER
+func ParseIndex(def string, global bool) (*Index, error) {
+	i := &Index{
+		Global:     global,
+		KeySchema:  map[string]string{},
+		Projection: "ALL",
+	}
+
+	for _, o := range strings.Split(def, ";") {
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid option %s in index definition %s", o, def)
+		}
+		opt, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		switch opt {
+		case "name":
+			i.Name = v
+			i.Ident = flect.New(v)
+		case "keySchema":
+			for _, k := range strings.Split(v, ",") {
+				key := strings.Split(k, ":")
+				if len(key) != 2 {
+					return nil, fmt.Errorf("Invalid key %s in index definition %s", k, def)
+				}
+				i.KeySchema[strings.ToUpper(strings.TrimSpace(key[1]))] = strings.TrimSpace(key[0])
+			}
+		case "projection":
+			i.Projection = strings.ToUpper(v)
+		case "nonKey":
+			for _, a := range strings.Split(v, ",") {
+				i.NonKeyAttributes = append(i.NonKeyAttributes, strings.TrimSpace(a))
+			}
+		case "overload":
+			i.Overload = v
+		case "pk":
+			i.PartitionKey = v
+		case "sk":
+			i.SortKey = v
+		case "read", "write":
+			c, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid capacity %s in index definition %s", v, def)
+			}
+			if i.CapacityUnits == nil {
+				i.CapacityUnits = map[string]int64{}
+			}
+			i.CapacityUnits[opt] = c
+		default:
+			return nil, fmt.Errorf("Unknown option %s in index definition %s", opt, def)
+		}
+	}
+
+	if len(i.Name) == 0 {
+		return nil, fmt.Errorf("No name given in index definition %s", def)
+	}
+
+	return i, nil
+}
+
+// parseIndexes parses the given index definitions and adds them to the model
+func (m *Model) parseIndexes(defs []string, global bool) error {
+	for _, d := range defs {
+		i, err := ParseIndex(d, global)
+		if err != nil {
+			return err
+		}
+		if m.SingleTable() {
+			err = m.checkOverload(i)
+		} else if len(i.Overload) > 0 || len(i.PartitionKey) > 0 || len(i.SortKey) > 0 {
+			err = fmt.Errorf("Overloaded index %s requires single-table mode", i.Name)
+		}
+		if err != nil {
+			return err
+		}
+		err = m.checkIndex(i)
+		if err != nil {
+			return err
+		}
+
+		// provisioned tables require capacity for their global indexes
+		if i.Global && m.BillingMode == "provisioned" {
+			if i.CapacityUnits == nil {
+				i.CapacityUnits = map[string]int64{}
+			}
+			for k, c := range m.CapacityUnits {
+				if _, ok := i.CapacityUnits[k]; !ok {
+					i.CapacityUnits[k] = c
+				}
+			}
+		}
+
+		m.Indexes = append(m.Indexes, i)
+	}
+
+	return nil
+}
+
+// checkIndex checks the index against the model's attributes, key schema and existing indexes
+func (m *Model) checkIndex(i *Index) error {
+	for _, e := range m.Indexes {
+		if e.Name == i.Name {
+			return fmt.Errorf("Index %s is defined more than once", i.Name)
+		}
+	}
+
+	hashKey, ok := i.KeySchema["HASH"]
+	if !ok {
+		return fmt.Errorf("No Hash Key defined for index %s", i.Name)
+	}
+	for t, k := range i.KeySchema {
+		if t != "HASH" && t != "RANGE" {
+			return fmt.Errorf("Invalid key type %s for index %s", t, i.Name)
+		}
+		a, ok := m.Attributes[k]
+		if !ok {
+			return fmt.Errorf("Key %s of index %s is not an attribute of %s", k, i.Name, m.Name)
+		}
+		if a.AwsType != "S" && a.AwsType != "N" && a.AwsType != "B" {
+			return fmt.Errorf("Key %s of index %s must be a string, number or binary attribute", k, i.Name)
+		}
+	}
+
+	if !i.Global {
+		if !m.CompositeKey {
+			return fmt.Errorf("Local index %s requires a Range Key on %s", i.Name, m.Name)
+		}
+		if hashKey != m.KeySchema["HASH"] {
+			return fmt.Errorf("Local index %s must use the Hash Key %s of %s", i.Name, m.KeySchema["HASH"], m.Name)
+		}
+		if _, ok := i.KeySchema["RANGE"]; !ok {
+			return fmt.Errorf("No Range Key defined for local index %s", i.Name)
+		}
+		if i.CapacityUnits != nil {
+			return fmt.Errorf("Local index %s shares the capacity of %s and cannot define its own", i.Name, m.Name)
+		}
+	}
+
+	switch i.Projection {
+	case "ALL", "KEYS_ONLY":
+		if len(i.NonKeyAttributes) > 0 {
+			return fmt.Errorf("Non-key attributes of index %s require the projection INCLUDE", i.Name)
+		}
+	case "INCLUDE":
+		for _, a := range i.NonKeyAttributes {
+			if !m.hasAttribute(a) {
+				return fmt.Errorf("Non-key attribute %s of index %s is not an attribute of %s", a, i.Name, m.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("Invalid projection %s for index %s. Please choose between ALL, KEYS_ONLY and INCLUDE", i.Projection, i.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/gobuffalo/flect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ManifestFile is the name of the declarative project manifest
+const ManifestFile = "dynql.yaml"
+
+// Manifest represents the declarative definition of the project's schemas, resources and functions
+type Manifest struct {
+	Schemas   map[string]*SchemaManifest   `yaml:"schemas"`
+	Functions map[string]*FunctionManifest `yaml:"functions,omitempty"`
+}
+
+// SchemaManifest represents a schema and its resources in the Manifest
+type SchemaManifest struct {
+	Path        string                       `yaml:"path,omitempty"`
+	SingleTable bool                         `yaml:"singleTable,omitempty"`
+	Resources   map[string]*ResourceManifest `yaml:"resources,omitempty"`
+}
+
+// ResourceManifest represents a resource in the Manifest
+type ResourceManifest struct {
+	Attributes     []string         `yaml:"attributes"`
+	KeySchema      string           `yaml:"keySchema,omitempty"`
+	Billing        string           `yaml:"billing,omitempty"`
+	Capacity       map[string]int64 `yaml:"capacity,omitempty"`
+	GSI            []*IndexManifest `yaml:"gsi,omitempty"`
+	LSI            []*IndexManifest `yaml:"lsi,omitempty"`
+	HasOne         []string         `yaml:"hasOne,omitempty"`
+	HasMany        []string         `yaml:"hasMany,omitempty"`
+	Versioned      bool             `yaml:"versioned,omitempty"`
+	PK             string           `yaml:"pk,omitempty"`
+	SK             string           `yaml:"sk,omitempty"`
+	ConsistentRead bool             `yaml:"consistentRead,omitempty"`
+}
+
+// IndexManifest represents a secondary index of a resource in the Manifest
+type IndexManifest struct {
+	Name             string           `yaml:"name"`
+	KeySchema        string           `yaml:"keySchema"`
+	Projection       string           `yaml:"projection,omitempty"`
+	NonKeyAttributes []string         `yaml:"nonKeyAttributes,omitempty"`
+	Capacity         map[string]int64 `yaml:"capacity,omitempty"`
+	Overload         string           `yaml:"overload,omitempty"`
+	PK               string           `yaml:"pk,omitempty"`
+	SK               string           `yaml:"sk,omitempty"`
+}
+
+// FunctionManifest represents a function in the Manifest
+type FunctionManifest struct {
+	Path   string `yaml:"path"`
+	Method string `yaml:"method"`
+}
+
+// ReadManifest reads the Manifest from dynql.yaml in the project path and sets the defaults of omitted values
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := helpers.ReadDataFromFile(filepath.Join(path, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	for n, s := range m.Schemas {
+		if s == nil {
+			s = &SchemaManifest{}
+			m.Schemas[n] = s
+		}
+		if len(s.Path) == 0 {
+			s.Path = n
+		}
+		s.Path = strings.TrimPrefix(s.Path, "/")
+
+		for rn, r := range s.Resources {
+			if r == nil {
+				r = &ResourceManifest{}
+				s.Resources[rn] = r
+			}
+			r.setDefaults()
+		}
+	}
+
+	for n, f := range m.Functions {
+		if f == nil || len(f.Path) == 0 || len(f.Method) == 0 {
+			return nil, fmt.Errorf("Function %s in %s requires a path and a method", n, ManifestFile)
+		}
+		f.Path = strings.TrimPrefix(f.Path, "/")
+	}
+
+	return &m, nil
+}
+
+// setDefaults sets the same defaults as the add resource command
+func (r *ResourceManifest) setDefaults() {
+	if len(r.KeySchema) == 0 {
+		r.KeySchema = "id:HASH"
+	}
+	if len(r.Billing) == 0 {
+		r.Billing = "provisioned"
+	}
+	if r.Capacity == nil {
+		r.Capacity = map[string]int64{}
+	}
+	for _, k := range []string{"read", "write"} {
+		if r.Capacity[k] == 0 {
+			r.Capacity[k] = 1
+		}
+	}
+}
+
+// AttributeString returns the attributes in the notation of the add resource command
+func (r ResourceManifest) AttributeString() string {
+	return strings.Join(r.Attributes, ",")
+}
+
+// Definition returns the index in the notation of the add resource command
+func (i IndexManifest) Definition() string {
+	opts := []string{
+		"name=" + i.Name,
+		"keySchema=" + i.KeySchema,
+	}
+	if len(i.Projection) > 0 {
+		opts = append(opts, "projection="+i.Projection)
+	}
+	if len(i.NonKeyAttributes) > 0 {
+		opts = append(opts, "nonKey="+strings.Join(i.NonKeyAttributes, ","))
+	}
+	for _, k := range []string{"read", "write"} {
+		if c, ok := i.Capacity[k]; ok {
+			opts = append(opts, k+"="+strconv.FormatInt(c, 10))
+		}
+	}
+	if len(i.Overload) > 0 {
+		opts = append(opts, "overload="+i.Overload)
+	}
+	if len(i.PK) > 0 {
+		opts = append(opts, "pk="+i.PK)
+	}
+	if len(i.SK) > 0 {
+		opts = append(opts, "sk="+i.SK)
+	}
+
+	return strings.Join(opts, ";")
+}
+
+// IndexDefinitions returns the global and local index definitions of the resource
+func (r ResourceManifest) IndexDefinitions() (gsi, lsi []string) {
+	for _, i := range r.GSI {
+		gsi = append(gsi, i.Definition())
+	}
+	for _, i := range r.LSI {
+		lsi = append(lsi, i.Definition())
+	}
+
+	return gsi, lsi
+}
+
+// Model returns the resource Model defined by the ResourceManifest, singleTable indicates whether its schema is in single-table mode.
+// Its relations are checked against the given resources.
+func (r ResourceManifest) Model(name, schema string, singleTable bool, resources map[string]*Resource) (*Model, error) {
+	options := r.options(schema, singleTable)
+	options["resources"] = resources
+
+	return New(name, false, r.AttributeString(), options)
+}
+
+// options returns the options of the resource Model in the notation of New
+func (r ResourceManifest) options(schema string, singleTable bool) map[string]interface{} {
+	gsi, lsi := r.IndexDefinitions()
+	return map[string]interface{}{
+		"keySchema":      r.KeySchema,
+		"billing":        r.Billing,
+		"capacity":       r.Capacity,
+		"gsi":            gsi,
+		"lsi":            lsi,
+		"hasOne":         r.HasOne,
+		"hasMany":        r.HasMany,
+		"schema":         schema,
+		"versioned":      r.Versioned,
+		"consistentRead": r.ConsistentRead,
+		"singleTable":    singleTable,
+		"pk":             r.PK,
+		"sk":             r.SK,
+	}
+}
+
+// Plain returns the ResourceManifest without its relations, references become attributes of the type of the referenced key
+func (r ResourceManifest) Plain(resources map[string]*Resource) *ResourceManifest {
+	p := r
+	p.HasOne, p.HasMany = nil, nil
+	p.Attributes = make([]string, len(r.Attributes))
+	for i, a := range r.Attributes {
+		p.Attributes[i] = plainAttribute(a, resources)
+	}
+
+	return &p
+}
+
+// plainAttribute replaces the type of a reference attribute like teacher_id:ref(Teacher)! by the type of the referenced key
+func plainAttribute(a string, resources map[string]*Resource) string {
+	var def string
+	if i := strings.Index(a, "="); i >= 0 {
+		a, def = a[:i], a[i:]
+	}
+	inputs := strings.SplitN(a, ":", 2)
+	if len(inputs) < 2 {
+		return a + def
+	}
+	goType := strings.TrimSuffix(inputs[1], "!")
+	match := refType.FindStringSubmatch(goType)
+	if match == nil {
+		return a + def
+	}
+
+	keyType := "string"
+	if target, ok := resources[flect.Camelize(match[2])]; ok {
+		if t, ok := target.hashGoType(); ok {
+			keyType = t
+		}
+	}
+	if match[1] == RefsRelation {
+		keyType = "[]" + keyType
+	}
+
+	return inputs[0] + ":" + keyType + strings.TrimPrefix(inputs[1], goType) + def
+}
+
+// Resources returns the resources declared in the Manifest keyed by the model name.
+// They are defined without their relations, so resources can reference each other regardless of the order they are added in.
+func (m Manifest) Resources(singleTable bool) (map[string]*Resource, error) {
+	rs := map[string]*Resource{}
+	for sn, s := range m.Schemas {
+		for rn, r := range s.Resources {
+			options := r.options(sn, singleTable || s.SingleTable)
+			options["relations"] = false
+			pm, err := New(rn, false, r.AttributeString(), options)
+			if err != nil {
+				return nil, fmt.Errorf("Resource %s: %s", rn, err)
+			}
+
+			res := pm.resource()
+			res.Schema = sn
+			rs[pm.Name] = res
+		}
+	}
+
+	return rs, nil
+}
+
+// ResourceSchemas returns the name of the schema each resource in the Manifest belongs to, keyed by the model name
+func (m Manifest) ResourceSchemas() map[string]string {
+	rs := map[string]string{}
+	for sn, s := range m.Schemas {
+		for rn := range s.Resources {
+			rs[flect.Camelize(rn)] = sn
+		}
+	}
+
+	return rs
+}
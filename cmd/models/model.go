@@ -1,37 +1,54 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/crolly/dynQL/cmd/helpers"
 	"github.com/gobuffalo/flect"
 )
 
+// VersionAttribute is the name of the attribute holding the version of a versioned resource
+const VersionAttribute = "version"
+
 // Model represents a resource model object
 type Model struct {
-	Name          string               `json:"name"`
-	Type          string               `json:"type"`
-	Ident         flect.Ident          `json:"ident"`
-	Attributes    map[string]Attribute `json:"attributes"`
-	Nested        []*Model             `json:"nested"`
-	Imports       []string             `json:"imports"`
-	KeySchema     map[string]string    `json:"key_schema"`
-	GeneratedID   bool                 `json:"generated_id"`
-	CompositeKey  bool                 `json:"composite_key"`
-	BillingMode   string               `json:"billing_mode"`
-	CapacityUnits map[string]int64     `json:"capacity_units"`
+	Name           string               `json:"name"`
+	Type           string               `json:"type"`
+	Ident          flect.Ident          `json:"ident"`
+	Attributes     map[string]Attribute `json:"attributes"`
+	Nested         []*Model             `json:"nested"`
+	Imports        []string             `json:"imports"`
+	KeySchema      map[string]string    `json:"key_schema"`
+	GeneratedID    bool                 `json:"generated_id"`
+	CompositeKey   bool                 `json:"composite_key"`
+	BillingMode    string               `json:"billing_mode"`
+	CapacityUnits  map[string]int64     `json:"capacity_units"`
+	Indexes        []*Index             `json:"indexes,omitempty"`
+	Relations      []*Relation          `json:"relations,omitempty"`
+	Versioned      bool                 `json:"versioned,omitempty"`
+	TableKeys      *TableKeys           `json:"table_keys,omitempty"`
+	ConsistentRead bool                 `json:"consistent_read,omitempty"`
 }
 
 // Attribute represents a resource model's attribute
 type Attribute struct {
-	Name    string      `json:"name"`
-	Ident   flect.Ident `json:"ident"`
-	GoType  string      `json:"go_type"`
-	AwsType string      `json:"aws_type"`
+	Name     string      `json:"name"`
+	Ident    flect.Ident `json:"ident"`
+	GoType   string      `json:"go_type"`
+	AwsType  string      `json:"aws_type"`
+	Required bool        `json:"required,omitempty"`
+	Default  string      `json:"default,omitempty"`
+	Enum     []string    `json:"enum,omitempty"`
 }
 
 // New returns a new model object
@@ -49,8 +66,14 @@ func New(name string, slice bool, attributes string, options map[string]interfac
 	}
 
 	// parse nested models
-	attributes = m.parseNested(attributes)
-	m.parseAttributes(attributes)
+	attributes, err := m.parseNested(attributes)
+	if err != nil {
+		return nil, err
+	}
+	err = m.parseAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
 
 	// handle all option values
 	var keySchema *string
@@ -59,6 +82,10 @@ func New(name string, slice bool, attributes string, options map[string]interfac
 		"read":  1,
 		"write": 1,
 	}
+	var gsi, lsi, hasOne, hasMany []string
+	var schema, pk, sk string
+	var resources map[string]*Resource
+	singleTable, relations := false, true
 	if options != nil {
 		if k, ok := options["keySchema"].(string); ok {
 			keySchema = &k
@@ -69,24 +96,128 @@ func New(name string, slice bool, attributes string, options map[string]interfac
 		if c, ok := options["capacity"].(map[string]int64); ok {
 			capacity = c
 		}
+		if g, ok := options["gsi"].([]string); ok {
+			gsi = g
+		}
+		if l, ok := options["lsi"].([]string); ok {
+			lsi = l
+		}
+		if h, ok := options["hasOne"].([]string); ok {
+			hasOne = h
+		}
+		if h, ok := options["hasMany"].([]string); ok {
+			hasMany = h
+		}
+		if s, ok := options["schema"].(string); ok {
+			schema = s
+		}
+		if r, ok := options["resources"].(map[string]*Resource); ok {
+			resources = r
+		}
+		if r, ok := options["relations"].(bool); ok {
+			relations = r
+		}
+		if s, ok := options["singleTable"].(bool); ok {
+			singleTable = s
+		}
+		if k, ok := options["pk"].(string); ok {
+			pk = k
+		}
+		if k, ok := options["sk"].(string); ok {
+			sk = k
+		}
+		if c, ok := options["consistentRead"].(bool); ok {
+			m.ConsistentRead = c
+		}
+		if v, ok := options["versioned"].(bool); ok && v {
+			err := m.addVersion()
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	err := m.parseKeySchema(keySchema)
+	err = m.parseKeySchema(keySchema)
 	if err != nil {
 		return nil, err
 	}
 
+	// resources in single-table mode build the keys of the shared table from their key templates
+	if singleTable {
+		err = m.parseTableKeys(pk, sk)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(pk) > 0 || len(sk) > 0 {
+		return nil, fmt.Errorf("Key templates of %s require single-table mode", m.Name)
+	}
+
 	m.BillingMode = strings.ToLower(billing)
 
 	if m.BillingMode == "provisioned" {
 		m.CapacityUnits = capacity
 	}
 
+	// parse secondary indexes after the key schema, local indexes depend on it
+	err = m.parseIndexes(lsi, false)
+	if err != nil {
+		return nil, err
+	}
+	err = m.parseIndexes(gsi, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !relations {
+		// the model is used without its relations, references keep the type string
+		m.Relations = nil
+		return m, nil
+	}
+
+	// relations are checked against the given resources or else the resources of the project
+	err = m.parseHasRelations(hasOne, HasOneRelation)
+	if err != nil {
+		return nil, err
+	}
+	err = m.parseHasRelations(hasMany, HasManyRelation)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Relations) > 0 || m.hasNestedRelations() {
+		if resources == nil {
+			c, err := ReadDQLConfig()
+			if err != nil {
+				return nil, err
+			}
+			resources = c.Resources
+		}
+		err = m.checkRelations(resources, schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
 
+// addVersion adds the version attribute used for optimistic locking to the model
+func (m *Model) addVersion() error {
+	if m.hasAttribute(VersionAttribute) {
+		return fmt.Errorf("Attribute %s is reserved for the version of versioned resources", VersionAttribute)
+	}
+	m.addAttribute(Attribute{
+		Name:    VersionAttribute,
+		Ident:   flect.New(VersionAttribute),
+		GoType:  "int64",
+		AwsType: helpers.AwsType("int64"),
+	})
+	m.Versioned = true
+
+	return nil
+}
+
 // parseNested parses the attributes string for nested models
-func (m *Model) parseNested(attributes string) string {
+func (m *Model) parseNested(attributes string) (string, error) {
 	var (
 		cob    []int        // curly opening bracket slice to remember position
 		cbc    = 0          // closing curly bracket counter
@@ -96,6 +227,10 @@ func (m *Model) parseNested(attributes string) string {
 		clAttr = attributes // cleared attribute string without nested parts
 	)
 	for pos, char := range attributes {
+		// the brackets of slice types like []byte do not belong to a nested model
+		if (char == '[' && strings.HasPrefix(attributes[pos:], "[]")) || (char == ']' && pos > 0 && attributes[pos-1] == '[') {
+			continue
+		}
 		if char == '{' {
 			// opening bracket
 			cob = append(cob, pos)
@@ -112,7 +247,10 @@ func (m *Model) parseNested(attributes string) string {
 		}
 
 		if len(cob) > 0 && len(cob) == cbc { // found single nested
-			cI := m.addNested(cob, pos, attributes, false)
+			cI, err := m.addNested(cob, pos, attributes, false)
+			if err != nil {
+				return "", err
+			}
 
 			// append nested part to rm slice
 			rm = append(rm, attributes[cI:pos+1])
@@ -122,7 +260,10 @@ func (m *Model) parseNested(attributes string) string {
 		}
 
 		if len(sob) > 0 && len(sob) == sbc { // found slice nested
-			cI := m.addNested(sob, pos, attributes, true)
+			cI, err := m.addNested(sob, pos, attributes, true)
+			if err != nil {
+				return "", err
+			}
 
 			// append nested part to rm slice
 			rm = append(rm, attributes[cI:pos+1])
@@ -136,11 +277,11 @@ func (m *Model) parseNested(attributes string) string {
 		clAttr = strings.Replace(clAttr, np, "", 1)
 	}
 
-	return clAttr
+	return clAttr, nil
 }
 
 // addNested adds a nested model to the resource model
-func (m *Model) addNested(b []int, pos int, attributes string, slice bool) int {
+func (m *Model) addNested(b []int, pos int, attributes string, slice bool) (int, error) {
 	// opening bracket index
 	bI := b[0]
 	// comma index
@@ -152,16 +293,26 @@ func (m *Model) addNested(b []int, pos int, attributes string, slice bool) int {
 	// new model name ensured to not have a comma or spaces
 	nmn := strings.Replace(strings.TrimSpace(attributes[cI:bI-1]), ",", "", 1)
 	attr := attributes[bI+1 : pos]
-	n, _ := New(nmn, slice, attr, nil)
+	n, err := New(nmn, slice, attr, nil)
+	if err != nil {
+		return 0, err
+	}
 
 	m.Nested = append(m.Nested, n)
 
-	return cI
+	return cI, nil
 }
 
-// parseAttributes parses all the attributes attached to a resource model
-func (m *Model) parseAttributes(attrs string) {
-	for _, a := range strings.Split(attrs, ",") {
+// parseAttributes parses all the attributes attached to a resource model.
+// An attribute is declared as name[:goType][!][=default], e.g. title:string! or status:enum(DRAFT,PUBLISHED)=DRAFT.
+// Required attributes (!) must be given and must not be empty, attributes with a default value are set to it if omitted
+func (m *Model) parseAttributes(attrs string) error {
+	for _, a := range helpers.SplitList(attrs) {
+		// the default value is split off first, it might contain colons like a time
+		var def string
+		if i := strings.Index(a, "="); i >= 0 {
+			a, def = a[:i], a[i+1:]
+		}
 		inputs := strings.Split(a, ":")
 		name := inputs[0]
 
@@ -173,18 +324,100 @@ func (m *Model) parseAttributes(attrs string) {
 		if len(inputs) > 1 {
 			goType = inputs[1]
 		}
+		required := strings.HasSuffix(name, "!") || strings.HasSuffix(goType, "!")
+		name, goType = strings.TrimSuffix(name, "!"), strings.TrimSuffix(goType, "!")
+
+		// references hold the key of another resource, its type is set when the relation is checked
+		if r := parseRef(name, goType); r != nil {
+			m.Relations = append(m.Relations, r)
+			goType = "string"
+			if r.Kind == RefsRelation {
+				goType = "[]string"
+			}
+		}
 
 		attr := Attribute{
-			Name:    name,
-			Ident:   flect.New(name),
-			GoType:  goType,
-			AwsType: helpers.AwsType(goType),
+			Name:     name,
+			Ident:    flect.New(name),
+			GoType:   goType,
+			AwsType:  helpers.AwsType(goType),
+			Required: required,
+			Default:  def,
+		}
+		values, err := parseEnum(goType)
+		if err != nil {
+			return fmt.Errorf("Invalid type of attribute %s: %s", name, err)
+		}
+		if values != nil {
+			// enums are strings restricted to the declared values
+			attr.GoType, attr.AwsType, attr.Enum = m.enumTypeName(name), "S", values
+		}
+		if err := attr.checkDefault(); err != nil {
+			return err
 		}
 
 		m.addImport(goType)
 
 		m.addAttribute(attr)
 	}
+
+	return nil
+}
+
+var uuidPattern = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+// checkDefault checks that the default value of the attribute can be assigned to it
+func (a Attribute) checkDefault() error {
+	if len(a.Default) == 0 {
+		return nil
+	}
+
+	err := checkDefault(a.GoType, a.Default)
+	if len(a.Enum) > 0 {
+		err = checkDefault("string", a.Default)
+		if err == nil && !helpers.Contains(a.Enum, a.Default) {
+			err = fmt.Errorf("%s is not a value of the enum", a.Default)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid default value of attribute %s: %s", a.Name, err)
+	}
+
+	return nil
+}
+
+// checkDefault checks that the default value can be assigned to an attribute of the given Go type
+func checkDefault(goType, def string) error {
+	if strings.ContainsAny(def, "\"`") {
+		return fmt.Errorf("%s must not contain quotes", def)
+	}
+
+	var err error
+	switch strings.TrimPrefix(goType, "*") {
+	case "string":
+	case "bool":
+		_, err = strconv.ParseBool(def)
+	case "int", "int8", "int16", "int32", "int64":
+		_, err = strconv.ParseInt(def, 10, 64)
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		_, err = strconv.ParseUint(def, 10, 64)
+	case "float32", "float64":
+		_, err = strconv.ParseFloat(def, 64)
+	case "time.Time":
+		_, err = time.Parse(time.RFC3339Nano, def)
+	case "uuid.UUID":
+		if !uuidPattern.MatchString(def) {
+			err = fmt.Errorf("%s is no UUID", def)
+		}
+	case "json.RawMessage":
+		if !json.Valid([]byte(def)) {
+			err = fmt.Errorf("%s is no JSON value", def)
+		}
+	default:
+		err = fmt.Errorf("Default values of type %s are not supported", goType)
+	}
+
+	return err
 }
 
 // addImport will add an import directive if the given type requires it
@@ -199,6 +432,199 @@ func (m *Model) addImport(goType string) {
 	}
 }
 
+// updateImports recalculates the import directives from the model's attributes
+func (m *Model) updateImports() {
+	m.Imports = nil
+	for _, n := range helpers.SortedKeys(m.Attributes) {
+		m.addImport(m.Attributes[n].GoType)
+	}
+}
+
+// AddAttributes parses the given attributes and nested models and adds them to the model.
+// It returns the names of the added attributes and nested models.
+func (m *Model) AddAttributes(attributes string) ([]string, error) {
+	// the added enums are named after the model
+	n := &Model{Ident: m.Ident}
+	attributes, err := n.parseNested(attributes)
+	if err != nil {
+		return nil, err
+	}
+	err = n.parseAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(n.Relations) > 0 || n.hasNestedRelations() {
+		return nil, fmt.Errorf("Relations of %s can only be declared with add resource", m.Name)
+	}
+
+	added := []string{}
+	for _, name := range helpers.SortedKeys(n.Attributes) {
+		if m.hasAttribute(name) {
+			return nil, fmt.Errorf("Attribute %s already exists in %s", name, m.Name)
+		}
+		if m.SingleTable() && m.tableAttribute(flect.Underscore(name)) {
+			return nil, fmt.Errorf("Attribute %s is reserved for the keys of the shared table", name)
+		}
+		m.addAttribute(n.Attributes[name])
+		added = append(added, name)
+	}
+	for _, nested := range n.Nested {
+		if m.hasAttribute(nested.Name) {
+			return nil, fmt.Errorf("Attribute %s already exists in %s", nested.Name, m.Name)
+		}
+		if m.SingleTable() && m.tableAttribute(nested.Ident.Underscore().String()) {
+			return nil, fmt.Errorf("Attribute %s is reserved for the keys of the shared table", nested.Name)
+		}
+		m.Nested = append(m.Nested, nested)
+		added = append(added, nested.Name)
+	}
+	m.updateImports()
+
+	return added, nil
+}
+
+// RemoveAttribute removes the attribute or nested model with the given name from the model
+func (m *Model) RemoveAttribute(name string) error {
+	for _, k := range m.KeySchema {
+		if flect.Camelize(k) == flect.Camelize(name) {
+			return fmt.Errorf("Attribute %s is part of the Key Schema and cannot be removed", name)
+		}
+	}
+	for _, i := range m.Indexes {
+		for _, k := range i.KeySchema {
+			if flect.Camelize(k) == flect.Camelize(name) {
+				return fmt.Errorf("Attribute %s is part of the Key Schema of index %s and cannot be removed", name, i.Name)
+			}
+		}
+	}
+	if m.Versioned && flect.Camelize(name) == flect.Camelize(VersionAttribute) {
+		return fmt.Errorf("Attribute %s holds the version of %s and cannot be removed", name, m.Name)
+	}
+	for _, r := range m.Relations {
+		if r.Kind != HasOneRelation && r.Kind != HasManyRelation && flect.Camelize(r.Key) == flect.Camelize(name) {
+			return fmt.Errorf("Attribute %s references %s in the relation %s and cannot be removed", name, r.Resource, r.Name)
+		}
+	}
+
+	for k := range m.Attributes {
+		if flect.Camelize(k) == flect.Camelize(name) {
+			delete(m.Attributes, k)
+			m.updateImports()
+			return nil
+		}
+	}
+	for i, n := range m.Nested {
+		if n.Name == flect.Camelize(name) {
+			m.Nested = append(m.Nested[:i], m.Nested[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Attribute %s does not exist in %s", name, m.Name)
+}
+
+// Retype changes the Go type of the attribute with the given name and returns the previous type
+func (m *Model) Retype(name, goType string) (string, error) {
+	if m.Versioned && flect.Camelize(name) == flect.Camelize(VersionAttribute) {
+		return "", fmt.Errorf("Attribute %s holds the version of %s and cannot be retyped", name, m.Name)
+	}
+	if strings.ContainsAny(goType, "!=") {
+		return "", fmt.Errorf("Invalid type %s. Retyping only changes the Go type of %s, not whether it is required or its default value", goType, name)
+	}
+	values, err := parseEnum(goType)
+	if err != nil {
+		return "", err
+	}
+	for k, a := range m.Attributes {
+		if flect.Camelize(k) == flect.Camelize(name) {
+			old := a.GoType
+			a.GoType = goType
+			a.AwsType = helpers.AwsType(goType)
+			a.Enum = values
+			if values != nil {
+				a.GoType, a.AwsType = m.enumTypeName(a.Name), "S"
+			}
+			if err := a.checkDefault(); err != nil {
+				return "", err
+			}
+			m.Attributes[k] = a
+			m.updateImports()
+			return old, nil
+		}
+	}
+
+	return "", fmt.Errorf("Attribute %s does not exist in %s", name, m.Name)
+}
+
+// hasAttribute checks whether the model has an attribute or nested model with the given name
+func (m *Model) hasAttribute(name string) bool {
+	for k := range m.Attributes {
+		if flect.Camelize(k) == flect.Camelize(name) {
+			return true
+		}
+	}
+	for _, n := range m.Nested {
+		if n.Name == flect.Camelize(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Patchable checks whether the model has an attribute or nested model besides its keys, which can be updated
+func (m Model) Patchable() bool {
+	if len(m.Nested) > 0 {
+		return true
+	}
+	keys := []string{}
+	for _, k := range m.KeySchema {
+		keys = append(keys, flect.Camelize(k))
+	}
+	for _, a := range m.Attributes {
+		if !helpers.Contains(keys, flect.Camelize(a.Name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeyAttribute returns the attribute with the given name, which is part of the key of the model or one of its indexes
+func (m Model) KeyAttribute(name string) Attribute {
+	for _, a := range m.Attributes {
+		if flect.Camelize(a.Name) == flect.Camelize(name) {
+			return a
+		}
+	}
+
+	// keys are strings unless their attribute declares another type
+	return Attribute{Name: name, Ident: flect.New(name), GoType: "string", AwsType: "S"}
+}
+
+// KeyAttributes returns the attributes of the keys of the model and its indexes ordered by name
+func (m Model) KeyAttributes() []Attribute {
+	names := map[string]bool{}
+	for _, k := range m.KeySchema {
+		names[flect.Underscore(k)] = true
+	}
+	for _, i := range m.Indexes {
+		for _, k := range i.KeySchema {
+			names[flect.Underscore(k)] = true
+		}
+	}
+
+	keys := []Attribute{}
+	for n := range names {
+		keys = append(keys, m.KeyAttribute(n))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Ident.Underscore().String() < keys[j].Ident.Underscore().String()
+	})
+
+	return keys
+}
+
 // GetImports recursively iterates through all import slices and adds the import to the root model
 func (m *Model) GetImports() []string {
 	var imports []string
@@ -294,8 +720,36 @@ func (m *Model) checkKeys() (bool, error) {
 
 // GetConfig returns the updated DQLConfig with the information from this Model
 func (m Model) GetConfig() (*DQLConfig, error) {
-	attributeDefinitions := map[string]AttributeDefinition{}
+	// update mug.config.json
+	r := m.resource()
+	c, err := ReadDQLConfig()
+	if err != nil {
+		return nil, err
+	}
+	if old, ok := c.Resources[m.Name]; ok {
+		// keep the schema the resource was added to
+		r.Schema = old.Schema
+	}
+	c.Resources[m.Name] = r
+
+	return c, nil
+}
+
+// resource returns the Resource definition of the Model with the key attributes of the table and its indexes
+func (m Model) resource() *Resource {
+	// key attributes of the table and its indexes need to be defined
+	keys := []string{}
 	for _, k := range m.KeySchema {
+		keys = append(keys, k)
+	}
+	for _, i := range m.Indexes {
+		for _, k := range i.KeySchema {
+			keys = append(keys, k)
+		}
+	}
+
+	attributeDefinitions := map[string]AttributeDefinition{}
+	for _, k := range keys {
 		a := m.Attributes[k]
 		if len(a.Name) > 0 {
 			attributeDefinitions[a.Name] = AttributeDefinition{
@@ -305,18 +759,34 @@ func (m Model) GetConfig() (*DQLConfig, error) {
 		}
 	}
 
-	// update mug.config.json
 	r := &Resource{
 		Ident:      flect.New(m.Name),
+		KeySchema:  m.KeySchema,
 		Attributes: attributeDefinitions,
 	}
-	c, err := ReadDQLConfig()
+	for _, i := range m.Indexes {
+		if r.Indexes == nil {
+			r.Indexes = map[string]map[string]string{}
+		}
+		r.Indexes[i.Name] = i.KeySchema
+	}
+
+	return r
+}
+
+// ReadModel reads the Model definition of the given resource from the modelName.json
+func ReadModel(path, name string) (*Model, error) {
+	data, err := helpers.ReadDataFromFile(filepath.Join(path, "models", fmt.Sprintf("%s.json", name)))
 	if err != nil {
 		return nil, err
 	}
-	c.Resources[m.Name] = r
 
-	return c, nil
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
 }
 
 // Write write the Model definition to the modelName.json
@@ -326,7 +796,102 @@ func (m Model) Write(path string) error {
 		return err
 	}
 
-	return ioutil.WriteFile(filepath.Join(path, "functions", m.Name, fmt.Sprintf("%s.json", m.Name)), json, 0644)
+	return ioutil.WriteFile(filepath.Join(path, "models", fmt.Sprintf("%s.json", m.Name)), json, 0644)
+}
+
+// Render renders the generated files of the resource into the given schema of the project, custom regions are preserved
+func (m *Model) Render(config *DQLConfig, schema string) error {
+	templates := map[string][]string{
+		"models": {
+			"model",
+			"model_test",
+			"resource",
+			"resource_test",
+			"scalars",
+			"scalars_test",
+		},
+		"schema": {
+			"schema",
+			"modelSchema",
+			"modelSchema_test",
+		},
+		"services": {
+			"batch",
+			"batch_test",
+			"dynamo",
+			"dynamo_test",
+			"filter",
+			"filter_test",
+			"loader",
+			"loader_test",
+			"service",
+			"service_test",
+			"table",
+			"table_test",
+			"transact",
+			"transact_test",
+		},
+		"main": {
+			"main_test",
+		},
+	}
+
+	data := map[string]interface{}{
+		"Config": config,
+		"Model":  m,
+		"Schema": schema,
+	}
+
+	projPath := config.ProjectPath
+
+	// iterate over schema templates and execute
+	for g, ts := range templates {
+		var f string
+		if g == "schema" {
+			f = filepath.Join(projPath, "handler", schema, "schema")
+		} else if g == "main" {
+			f = filepath.Join(projPath, "handler", schema)
+		} else {
+			f = filepath.Join(projPath, g)
+		}
+		err := os.MkdirAll(f, 0755)
+		if err != nil {
+			return err
+		}
+		for _, t := range ts {
+			tFile := t + ".tmpl"
+			fFile := t + ".go"
+			switch t {
+			case "resource", "resource_test":
+				fFile = strings.ReplaceAll(t, "resource", m.Ident.Camelize().String()) + ".go"
+			case "modelSchema", "modelSchema_test":
+				fFile = strings.ReplaceAll(t, "modelSchema", m.Ident.Camelize().String()) + ".go"
+			case "service", "service_test":
+				fFile = strings.ReplaceAll(t, "service", m.Ident.Camelize().String()) + ".go"
+			}
+			err = helpers.RenderFile(helpers.ResourceBox, fFile, tFile, f, data)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// the handler of the schema wires the loader of the services into each request
+	return helpers.RenderFile(helpers.SchemaBox, "main.go", "main.tmpl", filepath.Join(projPath, "handler", schema), data)
+}
+
+// Equals checks whether the given Model results in the same resource definition
+func (m Model) Equals(o Model) bool {
+	a, err := json.Marshal(m)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(a, b)
 }
 
 // String prints a representation of a model
@@ -357,15 +922,110 @@ func (m Model) String() string {
 	} else {
 		sb.WriteString("}\n")
 	}
+	for _, k := range helpers.SortedKeys(m.Attributes) {
+		if a := m.Attributes[k]; len(a.Enum) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(a.enumString(m))
+		}
+	}
 
 	return sb.String()
 }
 
-// String returns the string representation of an attribute
+// String returns the string representation of an attribute.
+// The dynql tag holds the constraints of the field in the GraphQL schema, keys and required attributes are non-null
 func (a Attribute) String(isKey bool) string {
 	omitempty := ",omitempty"
-	if isKey {
+	if isKey || a.Required {
 		omitempty = ""
 	}
-	return fmt.Sprintf("\t%s %s `json:\"%s%s\" dynamo:\"%s%s\"`", a.Ident.Pascalize(), a.GoType, a.Ident.Underscore(), omitempty, a.Ident.Underscore(), omitempty)
+	constraints := []string{}
+	switch {
+	case isKey:
+		constraints = append(constraints, "key")
+	case a.Required:
+		constraints = append(constraints, "required")
+	}
+	if len(a.Default) > 0 {
+		constraints = append(constraints, "default="+a.Default)
+	}
+	tag := ""
+	if len(constraints) > 0 {
+		tag = fmt.Sprintf(" dynql:\"%s\"", strings.Join(constraints, ","))
+	}
+
+	return fmt.Sprintf("\t%s %s `json:\"%s%s\" dynamo:\"%s%s\"%s`", a.Ident.Pascalize(), a.GoType, a.Ident.Underscore(), omitempty, a.Ident.Underscore(), omitempty, tag)
+}
+
+// GraphQLType returns the GraphQL type of the attribute as key argument within the generated models package.
+// Numbers are Ints, Int64s or Floats depending on their Go type and binary keys are base64 encoded
+func (a Attribute) GraphQLType() string {
+	goType := strings.TrimPrefix(a.GoType, "*")
+	switch a.AwsType {
+	case "N":
+		switch goType {
+		case "int64", "uint64":
+			return "Int64"
+		case "float32", "float64":
+			return "graphql.Float"
+		}
+		return "graphql.Int"
+	case "B":
+		return "Base64"
+	}
+
+	switch goType {
+	case "time.Time":
+		return "DateTime"
+	case "uuid.UUID":
+		return "UUID"
+	}
+
+	return "graphql.String"
+}
+
+// ArgType returns the Go type of the values of the attribute's key argument
+func (a Attribute) ArgType() string {
+	switch a.GraphQLType() {
+	case "graphql.Int":
+		return "int"
+	case "Int64":
+		return "int64"
+	case "graphql.Float":
+		return "float64"
+	case "Base64":
+		return "[]byte"
+	case "DateTime":
+		return "time.Time"
+	case "UUID":
+		return "uuid.UUID"
+	}
+
+	return "string"
+}
+
+// FakeValue returns a Go expression of a random value of the attribute's type for the generated tests
+func (a Attribute) FakeValue() string {
+	switch a.ArgType() {
+	case "int", "int64":
+		return fmt.Sprintf("%s(rand.Int31())", a.GoType)
+	case "float64":
+		return fmt.Sprintf("%s(rand.Float64())", a.GoType)
+	case "[]byte":
+		return fmt.Sprintf("%s(uuid.Must(uuid.NewV4()).Bytes())", a.GoType)
+	case "time.Time":
+		return "time.Now().UTC()"
+	case "uuid.UUID":
+		return "uuid.Must(uuid.NewV4())"
+	}
+	if a.GoType != "string" {
+		return fmt.Sprintf("%s(uuid.Must(uuid.NewV4()).String())", a.GoType)
+	}
+
+	return "uuid.Must(uuid.NewV4()).String()"
+}
+
+// ScalarName returns the name of the GraphQL Scalar of the attribute's key argument, e.g. to declare a variable of a query
+func (a Attribute) ScalarName() string {
+	return strings.TrimPrefix(a.GraphQLType(), "graphql.")
 }
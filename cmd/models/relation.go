@@ -0,0 +1,208 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/gobuffalo/flect"
+)
+
+// Kinds of relations between resources
+const (
+	// RefRelation resolves the resource referenced by the key held in an attribute (belongs-to)
+	RefRelation = "ref"
+	// RefsRelation resolves the resources referenced by the keys held in a list attribute
+	RefsRelation = "refs"
+	// HasOneRelation resolves the resource holding the key of the model in its foreign key
+	HasOneRelation = "hasOne"
+	// HasManyRelation resolves the resources holding the key of the model in their foreign key
+	HasManyRelation = "hasMany"
+)
+
+var refType = regexp.MustCompile(`^(refs?)\((\w+)\)$`)
+
+// Relation represents a relation of a resource model to another resource
+type Relation struct {
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	Ident     flect.Ident `json:"ident"`
+	Resource  flect.Ident `json:"resource"`
+	Key       string      `json:"key"`
+	Index     string      `json:"index,omitempty"`
+	Composite bool        `json:"composite,omitempty"`
+}
+
+// parseRef returns the relation for an attribute of the type ref(Resource) or refs(Resource) and nil otherwise.
+// The field of the relation is named after the attribute without its id suffix, e.g. teacher_id:ref(Teacher) becomes teacher.
+func parseRef(attribute, goType string) *Relation {
+	match := refType.FindStringSubmatch(goType)
+	if match == nil {
+		return nil
+	}
+
+	r := &Relation{
+		Kind:     match[1],
+		Resource: flect.New(flect.Camelize(match[2])),
+		Key:      attribute,
+	}
+
+	name := flect.Underscore(attribute)
+	switch {
+	case r.Kind == RefRelation && strings.HasSuffix(name, "_id"):
+		name = strings.TrimSuffix(name, "_id")
+	case r.Kind == RefsRelation && strings.HasSuffix(name, "_ids"):
+		name = flect.Pluralize(strings.TrimSuffix(name, "_ids"))
+	case r.Kind == RefRelation:
+		name = r.Resource.Singularize().Underscore().String()
+	default:
+		name = r.Resource.Pluralize().Underscore().String()
+	}
+	r.Name = name
+	r.Ident = flect.New(name)
+
+	return r
+}
+
+// parseHasRelations parses has-one or has-many definitions like lessons:Lesson:course_id and adds them to the model
+func (m *Model) parseHasRelations(defs []string, kind string) error {
+	for _, d := range defs {
+		def := strings.Split(d, ":")
+		if len(def) != 3 {
+			return fmt.Errorf("Invalid relation %s. Please use field:Resource:foreignKey", d)
+		}
+
+		name := flect.Underscore(strings.TrimSpace(def[0]))
+		m.Relations = append(m.Relations, &Relation{
+			Kind:     kind,
+			Name:     name,
+			Ident:    flect.New(name),
+			Resource: flect.New(flect.Camelize(strings.TrimSpace(def[1]))),
+			Key:      strings.TrimSpace(def[2]),
+		})
+	}
+
+	return nil
+}
+
+// hasNestedRelations checks whether any nested model declares a relation
+func (m *Model) hasNestedRelations() bool {
+	for _, n := range m.Nested {
+		if len(n.Relations) > 0 || n.hasNestedRelations() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRelations checks the relations of the model against the given resources.
+// The resources have to exist in the given schema and be queryable by the key of the relation.
+func (m *Model) checkRelations(resources map[string]*Resource, schema string) error {
+	if m.hasNestedRelations() {
+		return fmt.Errorf("Relations are only supported on the top-level attributes of %s", m.Name)
+	}
+
+	names := map[string]bool{}
+	for _, r := range m.Relations {
+		if m.hasAttribute(r.Name) || names[r.Name] {
+			return fmt.Errorf("Field %s of the relation to %s is already defined on %s", r.Name, r.Resource, m.Name)
+		}
+		names[r.Name] = true
+
+		// the model may reference itself before it is added to the config
+		target, ok := resources[r.Resource.String()]
+		if r.Resource.String() == m.Name {
+			target, ok = m.resource(), true
+			target.Schema = schema
+		}
+		if !ok {
+			return fmt.Errorf("Resource %s of the relation %s does not exist", r.Resource, r.Name)
+		}
+		if len(schema) > 0 && len(target.Schema) > 0 && target.Schema != schema {
+			return fmt.Errorf("Resource %s of the relation %s must be in the schema %s", r.Resource, r.Name, schema)
+		}
+		_, composite := target.KeySchema["RANGE"]
+		r.Composite = composite
+
+		var err error
+		switch r.Kind {
+		case RefRelation, RefsRelation:
+			err = m.checkRef(r, target)
+		case HasOneRelation, HasManyRelation:
+			err = m.checkHas(r, target)
+		default:
+			err = fmt.Errorf("Unknown kind %s of the relation %s", r.Kind, r.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRef checks that the referenced resource can be retrieved by its Hash Key only
+// and sets the type of the referencing attribute to the type of that key
+func (m *Model) checkRef(r *Relation, target *Resource) error {
+	if r.Composite {
+		return fmt.Errorf("Resource %s of the relation %s has a Range Key and cannot be referenced by %s alone", r.Resource, r.Name, r.Key)
+	}
+
+	goType, ok := target.hashGoType()
+	if !ok {
+		return fmt.Errorf("Resource %s of the relation %s can only be referenced by a string or number key", r.Resource, r.Name)
+	}
+	if r.Kind == RefsRelation {
+		goType = "[]" + goType
+	}
+
+	a := m.Attributes[r.Key]
+	a.GoType = goType
+	a.AwsType = helpers.AwsType(goType)
+	m.Attributes[r.Key] = a
+
+	return nil
+}
+
+// hashGoType returns the Go type of the attributes referencing the resource by its Hash Key, only string and number keys can be referenced
+func (r Resource) hashGoType() (string, bool) {
+	switch r.Attributes[r.KeySchema["HASH"]].AwsType {
+	case "S":
+		return "string", true
+	case "N":
+		return "int64", true
+	}
+
+	return "", false
+}
+
+// checkHas checks that the related resources can be queried by their foreign key,
+// either as the Hash Key of their table or of one of their indexes
+func (m *Model) checkHas(r *Relation, target *Resource) error {
+	if m.CompositeKey {
+		return fmt.Errorf("Relation %s requires %s to have a Hash Key only", r.Name, m.Name)
+	}
+
+	fk, ok := target.Attributes[r.Key]
+	if !ok {
+		return fmt.Errorf("Foreign key %s of the relation %s is not a key attribute of %s", r.Key, r.Name, r.Resource)
+	}
+	if fk.AwsType != m.Attributes[m.KeySchema["HASH"]].AwsType {
+		return fmt.Errorf("Foreign key %s of the relation %s does not match the type of the Hash Key of %s", r.Key, r.Name, m.Name)
+	}
+
+	if target.KeySchema["HASH"] == r.Key {
+		r.Index = ""
+		return nil
+	}
+	for _, n := range helpers.SortedKeys(target.Indexes) {
+		if target.Indexes[n]["HASH"] == r.Key {
+			r.Index = n
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Relation %s requires %s to have %s as the Hash Key of its table or of an index", r.Name, r.Resource, r.Key)
+}
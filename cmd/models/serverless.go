@@ -291,7 +291,7 @@ type GlobalIndex struct {
 	IndexName             string                 `yaml:"IndexName"`
 	KeySchema             []KeySchema            `yaml:"KeySchema"`
 	Projection            Projection             `yaml:"Projection"`
-	ProvisionedThroughput *ProvisionedThroughput `yaml:"ProvisionedTroughput,omitempty"`
+	ProvisionedThroughput *ProvisionedThroughput `yaml:"ProvisionedThroughput,omitempty"`
 }
 
 // Projection ...
@@ -390,9 +390,18 @@ func (s *ServerlessConfig) Write() error {
 	return nil
 }
 
-// SetResourceWithModel sets a Resource to the ServerlessConfig
-func (s *ServerlessConfig) SetResourceWithModel(c *DQLConfig, m *Model) {
+// SetResourceWithModel sets a Resource to the ServerlessConfig.
+// In single-table mode the Resource is stored in the table shared by the resources of its project or schema
+func (s *ServerlessConfig) SetResourceWithModel(c *DQLConfig, m *Model) error {
 	r := c.Resources[m.Name]
+	if len(s.Provider.Environments) == 0 {
+		s.Provider.Environments = map[string]string{}
+	}
+	if table := c.SharedTable(r.Schema); len(table) > 0 {
+		s.Provider.Environments[r.Ident.ToUpper().String()+"_TABLE_NAME"] = table + "-${opt:stage, self:provider.stage}"
+		return s.setSharedTable(c, table, m)
+	}
+
 	tableName := c.ProjectName + "-" + r.Ident.Pluralize().String() + "-${opt:stage, self:provider.stage}"
 	rd := &ResourceDefinition{
 		Type:           "AWS::DynamoDB::Table",
@@ -434,6 +443,50 @@ func (s *ServerlessConfig) SetResourceWithModel(c *DQLConfig, m *Model) {
 		rd.Properties.BillingMode = "PAY_PER_REQUEST"
 	}
 
+	// set secondary indexes
+	for _, i := range m.Indexes {
+		keySchema := []KeySchema{
+			{
+				AttributeName: i.KeySchema["HASH"],
+				KeyType:       "HASH",
+			},
+		}
+		if r, ok := i.KeySchema["RANGE"]; ok {
+			keySchema = append(keySchema, KeySchema{
+				AttributeName: r,
+				KeyType:       "RANGE",
+			})
+		}
+
+		projection := Projection{
+			ProjectionType: i.Projection,
+		}
+		for _, a := range i.NonKeyAttributes {
+			projection.NonKeyAttributes = append(projection.NonKeyAttributes, flect.Underscore(a))
+		}
+
+		if i.Global {
+			gi := GlobalIndex{
+				IndexName:  i.Name,
+				KeySchema:  keySchema,
+				Projection: projection,
+			}
+			if m.BillingMode == "provisioned" {
+				gi.ProvisionedThroughput = &ProvisionedThroughput{
+					ReadCapacityUnits:  i.CapacityUnits["read"],
+					WriteCapacityUnits: i.CapacityUnits["write"],
+				}
+			}
+			rd.Properties.GlobalSecondaryIndexes = append(rd.Properties.GlobalSecondaryIndexes, gi)
+		} else {
+			rd.Properties.LocalSecondaryIndexes = append(rd.Properties.LocalSecondaryIndexes, LocalIndex{
+				IndexName:  i.Name,
+				KeySchema:  keySchema,
+				Projection: projection,
+			})
+		}
+	}
+
 	if len(s.Resources.Resources) == 0 {
 		s.Resources = Resources{
 			Resources: map[string]*ResourceDefinition{},
@@ -442,11 +495,9 @@ func (s *ServerlessConfig) SetResourceWithModel(c *DQLConfig, m *Model) {
 	s.Resources.Resources[r.Ident.Pascalize().String()+"DynamoDbTable"] = rd
 
 	// set environment
-	if len(s.Provider.Environments) == 0 {
-		s.Provider.Environments = map[string]string{}
-	}
 	s.Provider.Environments[r.Ident.ToUpper().String()+"_TABLE_NAME"] = tableName
 
+	return nil
 }
 
 func (s *ServerlessConfig) removeResource(resourceName string) *ServerlessConfig {
@@ -0,0 +1,337 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/gobuffalo/flect"
+)
+
+// Attributes of the items in a table shared by the resources of a project or schema (single-table design)
+const (
+	PartitionKeyAttribute = "pk"
+	SortKeyAttribute      = "sk"
+	TypeAttribute         = "_type"
+)
+
+// TableKeys holds the templates the pk and sk of a resource in a shared table are built from, e.g. USER#{id}
+type TableKeys struct {
+	PartitionKey string `json:"partition_key"`
+	SortKey      string `json:"sort_key"`
+}
+
+// SingleTable checks whether the model is stored in a table shared with other resources
+func (m Model) SingleTable() bool {
+	return m.TableKeys != nil
+}
+
+// TypeName returns the value of the type discriminator of the model's items in a shared table
+func (m Model) TypeName() string {
+	return m.Ident.Singularize().Pascalize().String()
+}
+
+// keyPrefix returns the constant part of the default key templates, e.g. USER or LINE_ITEM
+func (m Model) keyPrefix() string {
+	return strings.ToUpper(m.Ident.Singularize().Underscore().String())
+}
+
+// parseTableKeys sets the key templates of a model stored in a shared table.
+// By default the pk is built from the type and the Hash Key and the sk from the type and the optional Range Key
+func (m *Model) parseTableKeys(pk, sk string) error {
+	for _, a := range []string{PartitionKeyAttribute, SortKeyAttribute, TypeAttribute} {
+		if m.reservedAttribute(a) {
+			return fmt.Errorf("Attribute %s is reserved for the keys of the shared table", a)
+		}
+	}
+
+	keys, err := m.keyTemplates(m.KeySchema, pk, sk)
+	if err != nil {
+		return err
+	}
+	m.TableKeys = keys
+
+	return nil
+}
+
+// keyTemplates checks the pk and sk templates against the given key schema and returns them with the attribute names of DynamoDB.
+// The pk may only contain the Hash Key and the sk only the Range Key, so the keys can be built from the key values alone
+func (m Model) keyTemplates(keySchema map[string]string, pk, sk string) (*TableKeys, error) {
+	hashKey, rangeKey := keySchema["HASH"], keySchema["RANGE"]
+	if len(pk) == 0 {
+		pk = m.keyPrefix() + "#{" + hashKey + "}"
+	}
+	if len(sk) == 0 {
+		sk = m.keyPrefix()
+		if len(rangeKey) > 0 {
+			sk += "#{" + rangeKey + "}"
+		}
+	}
+
+	pk, err := keyTemplate(pk, "partition key", hashKey)
+	if err != nil {
+		return nil, err
+	}
+	sk, err = keyTemplate(sk, "sort key", rangeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TableKeys{PartitionKey: pk, SortKey: sk}, nil
+}
+
+// keyTemplate checks that the template contains the placeholder of the given key attribute and no other one.
+// Without a key attribute the template has to be constant
+func keyTemplate(t, kind, key string) (string, error) {
+	var sb strings.Builder
+	found := false
+	for rest := t; ; {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			if strings.Contains(rest, "}") {
+				return "", fmt.Errorf("Unopened placeholder in the %s template %s", kind, t)
+			}
+			sb.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest, "}")
+		if end < start {
+			return "", fmt.Errorf("Unclosed placeholder in the %s template %s", kind, t)
+		}
+
+		name := rest[start+1 : end]
+		if len(key) == 0 {
+			return "", fmt.Errorf("The %s template %s must not contain placeholders", kind, t)
+		}
+		if flect.Camelize(name) != flect.Camelize(key) {
+			return "", fmt.Errorf("The %s template %s may only contain the placeholder {%s}", kind, t, key)
+		}
+		sb.WriteString(rest[:start] + "{" + flect.Underscore(key) + "}")
+		found = true
+		rest = rest[end+1:]
+	}
+
+	if len(key) > 0 && !found {
+		return "", fmt.Errorf("The %s template %s must contain the placeholder {%s}", kind, t, key)
+	}
+
+	return sb.String(), nil
+}
+
+// checkOverload checks a global index of a model in a shared table, which is stored in an overloaded index of the table
+func (m *Model) checkOverload(i *Index) error {
+	if !i.Global {
+		return fmt.Errorf("Local index %s is not supported in single-table mode. Please declare an overloaded global index instead", i.Name)
+	}
+	if len(i.Overload) == 0 {
+		return fmt.Errorf("Global index %s needs the overloaded index of the shared table it is stored in, e.g. overload=gsi1", i.Name)
+	}
+	if i.Projection != "ALL" {
+		return fmt.Errorf("Overloaded index %s projects all attributes, its projection cannot be changed", i.Name)
+	}
+	for _, e := range m.Indexes {
+		if e.Overload == i.Overload {
+			return fmt.Errorf("Indexes %s and %s are stored in the same overloaded index %s", e.Name, i.Name, i.Overload)
+		}
+	}
+	for _, a := range OverloadAttributes(i.Overload) {
+		if m.reservedAttribute(a) {
+			return fmt.Errorf("Attribute %s is reserved for the keys of the overloaded index %s", a, i.Overload)
+		}
+	}
+
+	keys, err := m.keyTemplates(i.KeySchema, i.PartitionKey, i.SortKey)
+	if err != nil {
+		return fmt.Errorf("Index %s: %s", i.Name, err)
+	}
+	i.PartitionKey, i.SortKey = keys.PartitionKey, keys.SortKey
+
+	return nil
+}
+
+// OverloadAttributes returns the names of the attributes holding the keys of an overloaded index, e.g. gsi1_pk and gsi1_sk
+func OverloadAttributes(overload string) []string {
+	return []string{overload + "_" + PartitionKeyAttribute, overload + "_" + SortKeyAttribute}
+}
+
+// tableAttribute checks whether the given DynamoDB name is used by the keys of the shared table or the model's overloaded indexes
+func (m Model) tableAttribute(name string) bool {
+	reserved := []string{PartitionKeyAttribute, SortKeyAttribute, TypeAttribute}
+	for _, i := range m.Indexes {
+		reserved = append(reserved, OverloadAttributes(i.Overload)...)
+	}
+
+	return helpers.Contains(reserved, name)
+}
+
+// reservedAttribute checks whether the model declares an attribute with exactly the given DynamoDB name
+func (m Model) reservedAttribute(name string) bool {
+	for _, a := range m.Attributes {
+		if a.Ident.Underscore().String() == name {
+			return true
+		}
+	}
+	for _, n := range m.Nested {
+		if n.Ident.Underscore().String() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SharedTable returns the name of the table shared by the resources of the given schema, which is empty
+// unless the project or the schema is in single-table mode
+func (c DQLConfig) SharedTable(schema string) string {
+	if c.SingleTable {
+		return c.ProjectName
+	}
+	if s, ok := c.Schemas[schema]; ok && s.SingleTable {
+		return c.ProjectName + "-" + flect.Camelize(schema)
+	}
+
+	return ""
+}
+
+// LocalTableName returns the name of the resource's table in the local DynamoDB for the given mode
+func (c DQLConfig) LocalTableName(r *Resource, mode string) string {
+	if table := c.SharedTable(r.Schema); len(table) > 0 {
+		return table + "-" + mode
+	}
+
+	return c.ProjectName + "-" + r.Ident.Pluralize().Camelize().String() + "-" + mode
+}
+
+// sharedTableResource returns the name of the CloudFormation resource of a shared table
+func sharedTableResource(table string) string {
+	return flect.Pascalize(table) + "SingleTable"
+}
+
+// sharedModels returns the models of the resources stored in the given shared table.
+// The given model replaces its stored definition, which might not be written yet
+func (c DQLConfig) sharedModels(table string, m *Model) ([]*Model, error) {
+	models := []*Model{}
+	for _, n := range helpers.SortedKeys(c.Resources) {
+		if c.SharedTable(c.Resources[n].Schema) != table {
+			continue
+		}
+		if m != nil && n == m.Name {
+			models = append(models, m)
+			continue
+		}
+		sm, err := ReadModel(c.ProjectPath, n)
+		if err != nil {
+			return nil, fmt.Errorf("No model definition found for resource %s: %s", n, err)
+		}
+		models = append(models, sm)
+	}
+
+	return models, nil
+}
+
+// newSharedTable returns the definition of a table shared by the given models with the generic pk and sk
+// and the overloaded indexes declared by them. A provisioned table has the summed capacity of its provisioned models
+func newSharedTable(table string, models []*Model) *ResourceDefinition {
+	rd := &ResourceDefinition{
+		Type:           "AWS::DynamoDB::Table",
+		DeletionPolicy: "Retain",
+		Properties: Properties{
+			TableName: table + "-${opt:stage, self:provider.stage}",
+			AttributeDefinitions: []AttributeDef{
+				{AttributeName: PartitionKeyAttribute, AttributeType: "S"},
+				{AttributeName: SortKeyAttribute, AttributeType: "S"},
+			},
+			KeySchema: []KeySchema{
+				{AttributeName: PartitionKeyAttribute, KeyType: "HASH"},
+				{AttributeName: SortKeyAttribute, KeyType: "RANGE"},
+			},
+		},
+	}
+
+	capacity := map[string]int64{}
+	overloads := map[string]map[string]int64{}
+	for _, m := range models {
+		provisioned := m.BillingMode == "provisioned"
+		if provisioned {
+			for k, c := range m.CapacityUnits {
+				capacity[k] += c
+			}
+		}
+		for _, i := range m.Indexes {
+			if _, ok := overloads[i.Overload]; !ok {
+				overloads[i.Overload] = map[string]int64{}
+			}
+			if provisioned {
+				for k, c := range i.CapacityUnits {
+					overloads[i.Overload][k] += c
+				}
+			}
+		}
+	}
+
+	provisioned := len(capacity) > 0
+	if provisioned {
+		rd.Properties.ProvisionedThroughput = newThroughput(capacity)
+	} else {
+		rd.Properties.BillingMode = "PAY_PER_REQUEST"
+	}
+
+	for _, o := range helpers.SortedKeys(overloads) {
+		attrs := OverloadAttributes(o)
+		rd.Properties.AttributeDefinitions = append(rd.Properties.AttributeDefinitions,
+			AttributeDef{AttributeName: attrs[0], AttributeType: "S"},
+			AttributeDef{AttributeName: attrs[1], AttributeType: "S"},
+		)
+		gi := GlobalIndex{
+			IndexName: o,
+			KeySchema: []KeySchema{
+				{AttributeName: attrs[0], KeyType: "HASH"},
+				{AttributeName: attrs[1], KeyType: "RANGE"},
+			},
+			Projection: Projection{ProjectionType: "ALL"},
+		}
+		if provisioned {
+			gi.ProvisionedThroughput = newThroughput(overloads[o])
+		}
+		rd.Properties.GlobalSecondaryIndexes = append(rd.Properties.GlobalSecondaryIndexes, gi)
+	}
+
+	return rd
+}
+
+// newThroughput returns the ProvisionedThroughput of the given capacity with at least one unit each
+func newThroughput(capacity map[string]int64) *ProvisionedThroughput {
+	t := &ProvisionedThroughput{
+		ReadCapacityUnits:  capacity["read"],
+		WriteCapacityUnits: capacity["write"],
+	}
+	if t.ReadCapacityUnits < 1 {
+		t.ReadCapacityUnits = 1
+	}
+	if t.WriteCapacityUnits < 1 {
+		t.WriteCapacityUnits = 1
+	}
+
+	return t
+}
+
+// setSharedTable sets the definition of the shared table to the ServerlessConfig, or removes it if no resource is stored in it anymore
+func (s *ServerlessConfig) setSharedTable(c *DQLConfig, table string, m *Model) error {
+	models, err := c.sharedModels(table, m)
+	if err != nil {
+		return err
+	}
+
+	if len(models) == 0 {
+		delete(s.Resources.Resources, sharedTableResource(table))
+		return nil
+	}
+	if len(s.Resources.Resources) == 0 {
+		s.Resources = Resources{
+			Resources: map[string]*ResourceDefinition{},
+		}
+	}
+	s.Resources.Resources[sharedTableResource(table)] = newSharedTable(table, models)
+
+	return nil
+}
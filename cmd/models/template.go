@@ -6,8 +6,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/crolly/dynQL/cmd/helpers"
-
 	"gopkg.in/yaml.v2"
 
 	"github.com/gobuffalo/flect"
@@ -62,7 +60,7 @@ type FnEnvironment struct {
 func NewTemplate(c *DQLConfig) (*TemplateConfig, error) {
 	// instantiate
 	t := &TemplateConfig{
-		ProjectPath: helpers.GetProjectPath(c.ProjectPath),
+		ProjectPath: c.ProjectPath,
 		Transform:   "AWS::Serverless-2016-10-31",
 		Globals: GlobalConfig{
 			Function: SAMFnProp{
@@ -83,7 +81,7 @@ func NewTemplate(c *DQLConfig) (*TemplateConfig, error) {
 	}
 
 	t.addFunctions(s)
-	t.addResourceEnvs(s)
+	t.addResourceEnvs(c)
 
 	return t, nil
 }
@@ -120,13 +118,11 @@ func (t *TemplateConfig) addFunctions(s *ServerlessConfig) {
 	}
 }
 
-func (t *TemplateConfig) addResourceEnvs(s *ServerlessConfig) {
+func (t *TemplateConfig) addResourceEnvs(c *DQLConfig) {
 	mode := os.Getenv("GRAPH_DYNAMO_MODE")
-	for n := range s.Resources.Resources {
-		nIdent := flect.New(strings.TrimSuffix(n, "DynamoDbTable"))
-		k := nIdent.Singularize().ToUpper().String() + "_TABLE_NAME"
-		v := s.Service.Name + "-" + nIdent.Pluralize().Camelize().String() + "-" + mode
-		t.setEnv(k, v)
+	for _, r := range c.Resources {
+		k := r.Ident.Singularize().ToUpper().String() + "_TABLE_NAME"
+		t.setEnv(k, c.LocalTableName(r, mode))
 	}
 }
 
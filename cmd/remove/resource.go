@@ -72,9 +72,10 @@ func init() {
 }
 
 func reRenderSchemaTemplate(config *models.DQLConfig, schema string) error {
-	f := filepath.Join(helpers.GetProjectPath(config.ProjectPath), "handler", schema, "schema")
+	f := filepath.Join(config.ProjectPath, "handler", schema, "schema")
 	data := map[string]interface{}{
 		"Config": config,
+		"Schema": schema,
 	}
 	return helpers.RenderFile(helpers.RemoveBox, "schema.go", "schema.tmpl", f, data)
 }
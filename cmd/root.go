@@ -24,17 +24,22 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/crolly/dynQL/cmd/data"
+
 	"github.com/crolly/dynQL/cmd/generate"
 
 	"github.com/crolly/dynQL/cmd/remove"
 
 	"github.com/crolly/dynQL/cmd/test"
 
+	"github.com/crolly/dynQL/cmd/update"
+
 	"github.com/crolly/dynQL/cmd/deploy"
 
 	"github.com/crolly/dynQL/cmd/debug"
 
 	"github.com/crolly/dynQL/cmd/add"
+	"github.com/crolly/dynQL/cmd/apply"
 	"github.com/crolly/dynQL/cmd/create"
 
 	"github.com/spf13/cobra"
@@ -55,11 +60,14 @@ dynql lets you ....`,
 func init() {
 	RootCmd.AddCommand(create.CreateCmd)
 	RootCmd.AddCommand(add.AddCmd)
+	RootCmd.AddCommand(apply.ApplyCmd)
 	RootCmd.AddCommand(debug.DebugCmd)
 	RootCmd.AddCommand(deploy.DeployCmd)
 	RootCmd.AddCommand(remove.RemoveCmd)
 	RootCmd.AddCommand(test.TestCmd)
+	RootCmd.AddCommand(update.UpdateCmd)
 	RootCmd.AddCommand(generate.GenerateTablesCmd)
+	RootCmd.AddCommand(data.DataCmd)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -41,6 +41,12 @@ var (
 				return err
 			}
 
+			// run everything from the project root
+			err = os.Chdir(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+
 			// create lambda-local network if it doesn't exist already
 			err = helpers.CreateLambdaNetwork()
 			if err != nil {
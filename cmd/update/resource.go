@@ -0,0 +1,142 @@
+// Copyright © 2019 Christian Rolly <mail@chromium-solutions.de>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package update
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/crolly/dynQL/cmd/models"
+	"github.com/gobuffalo/flect"
+	"github.com/spf13/cobra"
+)
+
+// resourceCmd represents the resource command
+var (
+	resourceCmd = &cobra.Command{
+		Use:   "resource name [flags]",
+		Short: "Update the attributes of a resource",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(addAttr) == 0 && len(removeAttr) == 0 && len(retype) == 0 {
+				return errors.New("Nothing to update. Please provide at least one of --add-attr, --remove-attr or --retype")
+			}
+
+			c, err := models.ReadDQLConfig()
+			if err != nil {
+				return err
+			}
+			m, err := models.ReadModel(c.ProjectPath, flect.Camelize(args[0]))
+			if err != nil {
+				return fmt.Errorf("No model definition found for resource %s: %s", args[0], err)
+			}
+
+			// apply changes to the model and collect summary
+			summary := []string{}
+			if len(addAttr) > 0 {
+				added, err := m.AddAttributes(addAttr)
+				if err != nil {
+					return err
+				}
+				for _, a := range added {
+					summary = append(summary, "+ "+a)
+				}
+			}
+			if len(removeAttr) > 0 {
+				for _, a := range strings.Split(removeAttr, ",") {
+					a = strings.TrimSpace(a)
+					err := m.RemoveAttribute(a)
+					if err != nil {
+						return err
+					}
+					summary = append(summary, "- "+a)
+				}
+			}
+			if len(retype) > 0 {
+				for _, r := range helpers.SplitList(retype) {
+					t := strings.Split(r, ":")
+					if len(t) != 2 {
+						return fmt.Errorf("Invalid retype definition %s. Please use name:goType", r)
+					}
+					old, err := m.Retype(t[0], t[1])
+					if err != nil {
+						return err
+					}
+					summary = append(summary, fmt.Sprintf("~ %s: %s -> %s", t[0], old, t[1]))
+				}
+			}
+
+			// update key attribute definitions in config
+			c, err = m.GetConfig()
+			if err != nil {
+				return err
+			}
+
+			// re-render the generated files of the resource
+			err = m.Render(c, c.Resources[m.Name].Schema)
+			if err != nil {
+				return err
+			}
+
+			// update serverless.yml
+			s, err := c.ReadServerlessConfig()
+			if err != nil {
+				return err
+			}
+			err = s.SetResourceWithModel(c, m)
+			if err != nil {
+				return err
+			}
+			err = s.Write()
+			if err != nil {
+				return err
+			}
+
+			// persist model definition and config
+			err = m.Write(c.ProjectPath)
+			if err != nil {
+				return err
+			}
+			err = c.Write()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Resource %s updated:\n", m.Name)
+			for _, s := range summary {
+				fmt.Println("  " + s)
+			}
+
+			return nil
+		},
+	}
+
+	addAttr, removeAttr, retype string
+)
+
+func init() {
+	UpdateCmd.AddCommand(resourceCmd)
+	resourceCmd.Flags().StringVar(&addAttr, "add-attr", "", "Attribute Definition of the Attributes to add (e.g. email,address:{street,zip})")
+	resourceCmd.Flags().StringVar(&removeAttr, "remove-attr", "", "Comma separated list of the Attributes to remove")
+	resourceCmd.Flags().StringVar(&retype, "retype", "", "Comma separated list of Attributes with their new Go type (e.g. age:int64 or status:enum(DRAFT,PUBLISHED))")
+}
@@ -0,0 +1,72 @@
+package update_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crolly/dynQL/cmd"
+	"github.com/crolly/dynQL/cmd/helpers"
+	"github.com/crolly/dynQL/cmd/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateResourceCmd(t *testing.T) {
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "test", "-s", "api")
+	folder := filepath.Join(wd, "test")
+	defer os.RemoveAll(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "user", "-s", "api", "-a", "id,name,age:int,address:{street,zip}")
+	assert.NoError(t, err)
+
+	// execute command to test
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "update", "resource", "user", "--add-attr", "email,joined:time.Time", "--remove-attr", "address", "--retype", "age:int64")
+	assert.NoError(t, err)
+
+	m, err := models.ReadModel(folder, "user")
+	assert.NoError(t, err)
+	assert.Contains(t, m.Attributes, "email")
+	assert.Equal(t, "int64", m.Attributes["age"].GoType)
+	assert.Empty(t, m.Nested)
+	assert.Equal(t, []string{"time"}, m.Imports)
+
+	data, _ := helpers.ReadDataFromFile(filepath.Join(folder, "models", "user.go"))
+	assert.Contains(t, string(data), "Email string")
+	assert.NotContains(t, string(data), "Address")
+
+	// key attributes cannot be removed
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "update", "resource", "user", "--add-attr=", "--retype=", "--remove-attr", "id")
+	assert.Error(t, err)
+}
+
+func TestUpdateResourceCmdBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the generated project downloads its dependencies")
+	}
+
+	wd, _ := helpers.GetWorkingDir()
+	defer os.Chdir(wd)
+
+	// create changes into the project folder
+	_, err := helpers.ExecuteCommand(cmd.RootCmd, "create", "example.com/up", "-s", "api")
+	folder := filepath.Join(wd, "up")
+	defer os.RemoveAll(folder)
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "add", "resource", "tag", "-s", "api", "-a", "id", "-k", "id:HASH")
+	assert.NoError(t, err)
+
+	_, err = helpers.ExecuteCommand(cmd.RootCmd, "update", "resource", "tag", "--remove-attr=", "--retype=", "--add-attr", "label")
+	assert.NoError(t, err)
+
+	// all generated files of the resource need to be re-rendered for the project to compile
+	data, _ := helpers.ReadDataFromFile(filepath.Join(folder, "models", "tag.go"))
+	assert.Contains(t, string(data), "Label string")
+	assert.NoError(t, helpers.RunCmd("go", "mod", "tidy"))
+	assert.NoError(t, helpers.RunCmd("go", "build", "./..."))
+}